@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const importUsage = `Usage: server import <file> [--format csv|jsonl] [--atomic=false] [--dry-run] [--json]
+
+Creates a book from every row of a CSV file with a header row or every line
+of a JSON Lines file; "-" reads standard input. CSV columns are title,
+author, year, isbn and description, in any order; other columns, such as
+those written by export, are ignored. By default nothing is created unless
+every row is valid and stored.
+
+Flags:
+`
+
+const exportUsage = `Usage: server export [--format csv|jsonl] [--output file]
+
+Writes every book outside the trash in ID order. CSV exports can be imported
+again with 'server import'.
+
+Flags:
+`
+
+// bookCSVHeader lists the columns of CSV exports
+var bookCSVHeader = []string{"id", "title", "author", "year", "isbn", "description", "version", "created_at", "updated_at"}
+
+// importRow is a decoded book and the line of the file it came from
+type importRow struct {
+	line int
+	book models.CreateBookRequest
+}
+
+// importError is a row that could not be imported
+type importError struct {
+	Line   int               `json:"line"`
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+type importResult struct {
+	Rows     int           `json:"rows"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	DryRun   bool          `json:"dry_run,omitempty"`
+	Errors   []importError `json:"errors,omitempty"`
+}
+
+// runImport creates books from a CSV or JSON Lines file
+func runImport(args []string) int {
+	flags := newFlagSet("import", importUsage)
+	out := addOutputFlag(flags)
+	format := flags.String("format", "", "csv or jsonl (default from the file extension, else csv)")
+	atomic := flags.Bool("atomic", true, "create no book unless every row succeeds")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return usageExit(err)
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	path := positional[0]
+	if *format == "" {
+		*format = "csv"
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".jsonl" || ext == ".ndjson" {
+			*format = "jsonl"
+		}
+	}
+
+	var read func(io.Reader) ([]importRow, []importError, error)
+	switch *format {
+	case "csv":
+		read = readCSVBooks
+	case "jsonl":
+		read = readJSONLBooks
+	default:
+		flags.Usage()
+		return exitUsage
+	}
+
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return out.fail(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	rows, rowErrors, err := read(input)
+	if err != nil {
+		return out.fail(fmt.Errorf("%s: %w", path, err))
+	}
+	result := importResult{Rows: len(rows) + len(rowErrors), DryRun: *dryRun}
+
+	validate := validator.New()
+	var ops []service.BulkOperation
+	var lines []int
+	for i := range rows {
+		if err := validate.Struct(&rows[i].book); err != nil {
+			rowErrors = append(rowErrors, importError{Line: rows[i].line, Error: "validation failed", Fields: utils.ValidationFields(err)})
+			continue
+		}
+		ops = append(ops, service.BulkOperation{Op: models.BulkOpCreate, Create: &rows[i].book})
+		lines = append(lines, rows[i].line)
+	}
+
+	if !*dryRun && len(ops) > 0 && (len(rowErrors) == 0 || !*atomic) {
+		ctx, stop := commandContext()
+		defer stop()
+
+		_, db, err := openDatabase(ctx)
+		if err != nil {
+			return out.fail(err)
+		}
+		defer db.Close()
+
+		outcomes := service.NewBookService(repository.NewGormBookRepository(db)).BulkApply(ctx, ops, *atomic)
+		for i, outcome := range outcomes {
+			switch {
+			case outcome.Err == nil:
+				result.Imported++
+			case !errors.Is(outcome.Err, service.ErrBulkRolledBack):
+				rowErrors = append(rowErrors, importError{Line: lines[i], Error: outcome.Err.Error()})
+			}
+		}
+	}
+
+	sort.Slice(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+	result.Errors = rowErrors
+	result.Failed = len(rowErrors)
+
+	text := fmt.Sprintf("✅ Imported %d of %d books\n", result.Imported, result.Rows)
+	if *dryRun {
+		text = fmt.Sprintf("✅ %d of %d books are valid (dry run)\n", result.Rows-result.Failed, result.Rows)
+	}
+	for _, rowError := range rowErrors {
+		text += fmt.Sprintf("❌ line %d: %s", rowError.Line, rowError.Error)
+		for field, message := range rowError.Fields {
+			text += fmt.Sprintf("; %s: %s", field, message)
+		}
+		text += "\n"
+	}
+	out.result(result, "%s", text)
+
+	if result.Failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// readCSVBooks decodes the rows of a CSV file with a header row
+func readCSVBooks(r io.Reader) ([]importRow, []importError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading the header row: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author", "year"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("the header row has no %s column", required)
+		}
+	}
+
+	var rows []importRow
+	var rowErrors []importError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, rowErrors, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		year, err := strconv.Atoi(field("year"))
+		if err != nil && field("year") != "" {
+			rowErrors = append(rowErrors, importError{Line: line, Error: fmt.Sprintf("year %q is not a number", field("year"))})
+			continue
+		}
+
+		rows = append(rows, importRow{line: line, book: models.CreateBookRequest{
+			Title:       field("title"),
+			Author:      field("author"),
+			Year:        year,
+			ISBN:        field("isbn"),
+			Description: field("description"),
+		}})
+	}
+}
+
+// readJSONLBooks decodes a JSON Lines file, one book per line
+func readJSONLBooks(r io.Reader) ([]importRow, []importError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []importRow
+	var rowErrors []importError
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var book models.CreateBookRequest
+		if err := json.Unmarshal(scanner.Bytes(), &book); err != nil {
+			rowErrors = append(rowErrors, importError{Line: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, importRow{line: line, book: book})
+	}
+
+	return rows, rowErrors, scanner.Err()
+}
+
+// runExport writes every book as CSV or JSON Lines
+func runExport(args []string) int {
+	flags := newFlagSet("export", exportUsage)
+	format := flags.String("format", "csv", "csv or jsonl")
+	outputPath := flags.String("output", "-", `file to write, "-" for standard output`)
+	if positional, err := parseArgs(flags, args); err != nil || len(positional) > 0 {
+		if err == nil {
+			flags.Usage()
+		}
+		return usageExit(err)
+	}
+	if *format != "csv" && *format != "jsonl" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	out := &output{}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	_, db, err := openDatabase(ctx)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer db.Close()
+
+	file := os.Stdout
+	if *outputPath != "-" {
+		if file, err = os.Create(*outputPath); err != nil {
+			return out.fail(err)
+		}
+		defer file.Close()
+	}
+	buffered := bufio.NewWriter(file)
+
+	var write func(*models.Book) error
+	flush := buffered.Flush
+	switch *format {
+	case "csv":
+		writer := csv.NewWriter(buffered)
+		if err := writer.Write(bookCSVHeader); err != nil {
+			return out.fail(err)
+		}
+		write = func(book *models.Book) error {
+			return writer.Write([]string{
+				strconv.FormatUint(uint64(book.ID), 10),
+				book.Title,
+				book.Author,
+				strconv.Itoa(book.Year),
+				book.ISBN,
+				book.Description,
+				strconv.FormatUint(uint64(book.Version), 10),
+				book.CreatedAt.UTC().Format(time.RFC3339Nano),
+				book.UpdatedAt.UTC().Format(time.RFC3339Nano),
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return err
+			}
+			return buffered.Flush()
+		}
+	case "jsonl":
+		encoder := json.NewEncoder(buffered)
+		write = func(book *models.Book) error {
+			return encoder.Encode(book)
+		}
+	}
+
+	exported := 0
+	err = service.NewBookService(repository.NewGormBookRepository(db)).ExportBooks(ctx, func(book *models.Book) error {
+		exported++
+		return write(book)
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil && file != os.Stdout {
+		err = file.Close()
+	}
+	if err != nil {
+		return out.fail(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Exported %d books\n", exported)
+	return exitOK
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"library-backend/internal/config"
+	"library-backend/internal/requestctx"
+	"library-backend/pkg/database"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Exit codes of the commands
+const (
+	exitOK    = 0
+	exitError = 1 // the command failed
+	exitUsage = 2 // invalid arguments
+)
+
+// cliActor is recorded in the audit trail for changes made by commands
+const cliActor = "cli"
+
+// command is a subcommand of the server binary
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+var commands = []command{
+	{"serve", "run the HTTP server (the default)", runServe},
+	{"migrate", "apply, revert or list schema migrations", runMigrate},
+	{"seed", "insert sample books into an empty database", runSeed},
+	{"import", "create books from a CSV or JSON Lines file", runImport},
+	{"export", "write every book as CSV or JSON Lines", runExport},
+	{"user", "create users for the admin endpoints", runUser},
+	{"reindex", "rebuild table indexes and planner statistics", runReindex},
+	{"config", "validate the configuration", runConfig},
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, "Usage: server <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s%s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprint(w, "\nRun 'server <command> -h' for the arguments of a command. Commands\n"+
+		"share the server's configuration, exit with 1 on failure and 2 on\n"+
+		"invalid arguments, and write JSON results when given --json.\n")
+}
+
+// newFlagSet returns a flag set printing usage followed by the flags on -h
+// and on parse errors
+func newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses flags given before, between or after the positional
+// arguments and returns the positional arguments
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageExit returns the exit code for a flag parse error; the flag set has
+// printed the usage already
+func usageExit(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// output writes command results to stdout and failures to stderr, as JSON
+// when the command is given --json
+type output struct {
+	json bool
+}
+
+func addOutputFlag(flags *flag.FlagSet) *output {
+	out := &output{}
+	flags.BoolVar(&out.json, "json", false, "write the result as JSON")
+	return out
+}
+
+// result writes v as JSON, or the text formatted from format and args
+func (o *output) result(v interface{}, format string, args ...interface{}) {
+	if o.json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(v)
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// fail reports err and returns exitError
+func (o *output) fail(err error) int {
+	if o.json {
+		json.NewEncoder(os.Stderr).Encode(map[string]string{"error": err.Error()})
+	} else {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	}
+	return exitError
+}
+
+// commandContext is cancelled on SIGINT and SIGTERM and attributes audited
+// changes to the CLI
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	return requestctx.WithActor(ctx, cliActor), stop
+}
+
+// openDatabase connects to the configured database and fails if migrations
+// are pending, since commands expect the current schema
+func openDatabase(ctx context.Context) (*config.Config, *database.Database, error) {
+	cfg := config.Load()
+
+	db, err := database.NewGormDB(&cfg.Database)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	states, err := db.MigrationStatus(ctx)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	for _, state := range states {
+		if !state.Applied {
+			db.Close()
+			return nil, nil, errors.New("migrations are pending, run 'server migrate up' first")
+		}
+	}
+
+	return cfg, db, nil
+}
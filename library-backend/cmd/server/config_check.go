@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"library-backend/internal/config"
+	"strings"
+)
+
+const configUsage = `Usage: server config check [--json]
+
+Loads the configuration from the environment and .env like the server does
+and reports values that cannot be parsed or used. Exits with 1 if there are
+any. The JSON output includes the effective configuration without secrets.
+
+Flags:
+`
+
+type configCheckResult struct {
+	Valid    bool           `json:"valid"`
+	Problems []string       `json:"problems"`
+	Config   *config.Config `json:"config"`
+}
+
+// runConfig runs a config subcommand; check is the only one
+func runConfig(args []string) int {
+	flags := newFlagSet("config", configUsage)
+	out := addOutputFlag(flags)
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return usageExit(err)
+	}
+	if len(positional) != 1 || positional[0] != "check" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cfg := config.Load()
+	result := configCheckResult{Valid: true, Problems: []string{}, Config: cfg}
+	if err := cfg.Validate(); err != nil {
+		result.Valid = false
+		result.Problems = strings.Split(err.Error(), "\n")
+	}
+
+	if result.Valid {
+		out.result(result, "✅ Configuration is valid\n")
+		return exitOK
+	}
+	if out.json {
+		out.result(result, "")
+		return exitError
+	}
+	return out.fail(errors.New("invalid configuration:\n  " + strings.Join(result.Problems, "\n  ")))
+}
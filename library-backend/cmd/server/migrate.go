@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"library-backend/internal/config"
+	"library-backend/pkg/database"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = `Usage: server migrate <command> [--json]
+
+Commands:
+  up                  apply every pending migration
+  down [steps]        revert the latest applied migrations (default 1)
+  status              list migrations and whether they are applied
+  create <name>       write empty up/down SQL files for a new migration, one
+                      pair per database driver (--dir, default
+                      pkg/database/migrations)
+
+Flags:
+`
+
+// migrationRef identifies a migration in the output of migrate up and down
+type migrationRef struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+}
+
+func migrationRefs(migrations []database.Migration) []migrationRef {
+	refs := make([]migrationRef, 0, len(migrations))
+	for _, migration := range migrations {
+		refs = append(refs, migrationRef{Version: migration.Version, Name: migration.Name})
+	}
+	return refs
+}
+
+// runMigrate runs a migrate subcommand and returns the process exit code
+func runMigrate(args []string) int {
+	flags := newFlagSet("migrate", migrateUsage)
+	out := addOutputFlag(flags)
+	dir := flags.String("dir", "pkg/database/migrations", "directory holding a migration directory per driver, for create")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return usageExit(err)
+	}
+	if len(positional) == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if positional[0] == "create" {
+		if len(positional) != 2 {
+			flags.Usage()
+			return exitUsage
+		}
+
+		files, err := database.CreateMigration(*dir, positional[1])
+		if err != nil {
+			return out.fail(err)
+		}
+		out.result(map[string][]string{"files": files}, "%s\n", strings.Join(files, "\n"))
+		return exitOK
+	}
+
+	steps := 1
+	switch {
+	case positional[0] != "up" && positional[0] != "down" && positional[0] != "status":
+		flags.Usage()
+		return exitUsage
+	case positional[0] == "down" && len(positional) == 2:
+		if steps, err = strconv.Atoi(positional[1]); err != nil || steps < 1 {
+			flags.Usage()
+			return exitUsage
+		}
+	case len(positional) != 1:
+		flags.Usage()
+		return exitUsage
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	// Unlike the other commands this works on a database with pending
+	// migrations, so it connects without openDatabase
+	cfg := config.Load()
+	db, err := database.NewGormDB(&cfg.Database)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer db.Close()
+
+	switch positional[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx)
+		if err != nil {
+			// Migrations before the failing one stay applied
+			for _, migration := range applied {
+				fmt.Fprintf(os.Stderr, "✅ Applied %d_%s\n", migration.Version, migration.Name)
+			}
+			return out.fail(err)
+		}
+
+		text := "Schema is up to date\n"
+		if len(applied) > 0 {
+			text = ""
+			for _, migration := range applied {
+				text += fmt.Sprintf("✅ Applied %d_%s\n", migration.Version, migration.Name)
+			}
+		}
+		out.result(map[string][]migrationRef{"applied": migrationRefs(applied)}, "%s", text)
+
+	case "down":
+		reverted, err := db.MigrateDown(ctx, steps)
+		if err != nil {
+			for _, migration := range reverted {
+				fmt.Fprintf(os.Stderr, "↩️ Reverted %d_%s\n", migration.Version, migration.Name)
+			}
+			return out.fail(err)
+		}
+
+		text := ""
+		for _, migration := range reverted {
+			text += fmt.Sprintf("↩️ Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		out.result(map[string][]migrationRef{"reverted": migrationRefs(reverted)}, "%s", text)
+
+	case "status":
+		states, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return out.fail(err)
+		}
+
+		if out.json {
+			out.result(states, "")
+			break
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != nil {
+				appliedAt = state.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", state.Version, state.Name, appliedAt)
+		}
+		w.Flush()
+	}
+
+	return exitOK
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+)
+
+const reindexUsage = `Usage: server reindex [table...] [--json]
+
+Rebuilds the indexes of the given tables, or of every table, and refreshes
+their planner statistics. Writes to a table wait while it is reindexed.
+
+Flags:
+`
+
+// runReindex rebuilds table indexes and statistics
+func runReindex(args []string) int {
+	flags := newFlagSet("reindex", reindexUsage)
+	out := addOutputFlag(flags)
+	tables, err := parseArgs(flags, args)
+	if err != nil {
+		return usageExit(err)
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	_, db, err := openDatabase(ctx)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer db.Close()
+
+	reindexed, err := db.Reindex(ctx, tables...)
+	if err != nil {
+		return out.fail(err)
+	}
+
+	text := ""
+	for _, table := range reindexed {
+		text += fmt.Sprintf("✅ Reindexed %s\n", table)
+	}
+	out.result(map[string][]string{"tables": reindexed}, "%s", text)
+	return exitOK
+}
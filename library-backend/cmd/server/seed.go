@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"library-backend/internal/models"
+	"library-backend/internal/utils"
+	"library-backend/pkg/database"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const seedUsage = `Usage: server seed [--fixture file] [--json]
+
+Inserts the sample books, or the books of a fixture, unless the database
+already holds books. A fixture is a JSON array of books in the format of
+POST /api/v1/books.
+
+Flags:
+`
+
+// runSeed inserts sample books into an empty database
+func runSeed(args []string) int {
+	flags := newFlagSet("seed", seedUsage)
+	out := addOutputFlag(flags)
+	fixture := flags.String("fixture", "", "JSON file of the books to insert instead of the samples")
+	if positional, err := parseArgs(flags, args); err != nil || len(positional) > 0 {
+		if err == nil {
+			flags.Usage()
+		}
+		return usageExit(err)
+	}
+
+	books := database.SampleBooks()
+	if *fixture != "" {
+		var err error
+		if books, err = loadFixture(*fixture); err != nil {
+			return out.fail(err)
+		}
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	_, db, err := openDatabase(ctx)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer db.Close()
+
+	seeded, err := db.SeedBooks(ctx, books)
+	if err != nil {
+		return out.fail(err)
+	}
+
+	text := fmt.Sprintf("🌱 Seeded %d books\n", seeded)
+	if seeded == 0 {
+		text = "📚 Books already exist, nothing seeded\n"
+	}
+	out.result(map[string]int{"seeded": seeded}, "%s", text)
+	return exitOK
+}
+
+// loadFixture reads and validates the books of a fixture file
+func loadFixture(path string) ([]models.Book, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []models.CreateBookRequest
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	validate := validator.New()
+	books := make([]models.Book, 0, len(requests))
+	for i := range requests {
+		if err := validate.Struct(&requests[i]); err != nil {
+			return nil, fmt.Errorf("%s: book %d is invalid: %v", path, i+1, utils.ValidationFields(err))
+		}
+		books = append(books, *requests[i].ToModel())
+	}
+
+	return books, nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"library-backend/internal/api/handlers"
+	"library-backend/internal/api/middleware"
+	"library-backend/internal/config"
+	"library-backend/internal/health"
+	"library-backend/internal/jobs"
+	"library-backend/internal/metrics"
+	"library-backend/internal/repository"
+	"library-backend/internal/service"
+	"library-backend/internal/tracing"
+	"library-backend/pkg/database"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+const serveUsage = `Usage: server serve
+
+Runs the HTTP server until SIGINT or SIGTERM, applying pending migrations
+first unless DB_MIGRATE_ON_START=false. Exits with status 2 when the
+configuration is invalid.
+`
+
+// Spans still buffered at shutdown get this long to be exported
+const traceFlushTimeout = 5 * time.Second
+
+// runServe runs the HTTP server until SIGINT or SIGTERM
+func runServe(args []string) int {
+	flags := newFlagSet("serve", serveUsage)
+	if positional, err := parseArgs(flags, args); err != nil || len(positional) > 0 {
+		if err == nil {
+			flags.Usage()
+		}
+		return usageExit(err)
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Initialize logger
+	logger := newLogger(&cfg.App)
+
+	// Set Gin mode
+	gin.SetMode(cfg.Server.Mode)
+
+	logger.Infof("🚀 Starting %s v%s", cfg.App.Name, cfg.App.Version)
+
+	// Refuse to run on values that were unparseable or out of range rather
+	// than on defaults the operator did not choose
+	if err := cfg.Validate(); err != nil {
+		logger.WithError(err).Error("❌ Invalid configuration; run 'server config check' for details")
+		return exitUsage
+	}
+
+	// Cancelled on SIGINT or SIGTERM to start the shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(&cfg.Tracing, &cfg.App)
+	if err != nil {
+		logger.WithError(err).Fatal("❌ Failed to set up tracing")
+	}
+
+	// Initialize database
+	db, err := database.NewGormDB(&cfg.Database)
+	if err != nil {
+		logger.WithError(err).Fatal("❌ Failed to connect to database")
+	}
+
+	// Health checks; other subsystems register theirs below
+	healthRegistry := health.NewRegistry()
+	for _, check := range db.HealthChecks() {
+		healthRegistry.Register(check)
+	}
+
+	// Initialize repositories
+	bookRepository := repository.NewGormBookRepository(db)
+	urlLogRepository := repository.NewGormURLLogRepository(db)
+
+	// Initialize services
+	bookService := service.NewBookService(bookRepository)
+	urlRuleService := service.NewURLRuleService(db)
+	trackingParamService := service.NewTrackingParamService(db)
+	urlPolicy := service.NewURLPolicy(&cfg.URLPolicy)
+	urlResolver := service.NewURLResolver(&cfg.Resolver, urlPolicy)
+	ipAnonymizer := service.NewIPAnonymizer(&cfg.URLLogs)
+	urlService := service.NewURLService(urlLogRepository, urlRuleService, trackingParamService, urlResolver, ipAnonymizer, urlPolicy)
+	urlLogService := service.NewURLLogService(urlLogRepository, db, ipAnonymizer)
+	auditService := service.NewAuditService(db)
+	shortLinkService := service.NewShortLinkService(db, urlService, ipAnonymizer, &cfg.ShortLinks)
+	userService := service.NewUserService(db)
+
+	// Initialize handlers
+	bookHandler := handlers.NewBookHandler(bookService, &cfg.Server)
+	urlHandler := handlers.NewURLHandler(urlService, &cfg.URLBatch)
+	urlRuleHandler := handlers.NewURLRuleHandler(urlRuleService)
+	trackingParamHandler := handlers.NewTrackingParamHandler(trackingParamService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	shortLinkHandler := handlers.NewShortLinkHandler(shortLinkService, &cfg.ShortLinks)
+	urlLogHandler := handlers.NewURLLogHandler(urlLogService, &cfg.Server)
+	healthHandler := handlers.NewHealthHandler(healthRegistry, &cfg.App)
+
+	// Background jobs, started once the schema is migrated
+	scheduler := jobs.NewScheduler(logger)
+	scheduler.Register(jobs.TrashPurgeJob(bookService, &cfg.Trash, logger))
+	scheduler.Register(jobs.URLLogRetentionJob(urlLogService, &cfg.URLLogs, logger))
+	healthRegistry.Register(scheduler.HealthCheck())
+
+	// Setup router with middleware
+	router := gin.New()
+
+	// Global middleware
+	router.Use(middleware.Tracing())
+	router.Use(middleware.RequestID(logger))
+	router.Use(middleware.Logger())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.CORS())
+	router.Use(middleware.ErrorHandler())
+	router.Use(gin.Recovery())
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Swagger endpoint
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	adminAuth := middleware.AdminAuth(&cfg.Admin, userService)
+
+	// Health checks
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/health", healthHandler.Health)
+	router.GET("/health/details", adminAuth, healthHandler.Details)
+
+	// API routes
+	api := router.Group("/api/v1")
+	{
+		// Books endpoints
+		books := api.Group("/books")
+		{
+			books.GET("", bookHandler.GetBooks)
+			books.POST("", bookHandler.CreateBook)
+			books.GET("/search", bookHandler.SearchBooks)
+			books.POST("/bulk", bookHandler.BulkBooks)
+			books.GET("/trash", bookHandler.GetTrash)
+			books.GET("/:id", bookHandler.GetBook)
+			books.PUT("/:id", middleware.RequireIfMatch(cfg.Server.RequireIfMatch), bookHandler.UpdateBook)
+			books.PATCH("/:id", middleware.RequireIfMatch(cfg.Server.RequireIfMatch), bookHandler.PatchBook)
+			books.DELETE("/:id",
+				middleware.AdminAuthWhen(&cfg.Admin, userService, handlers.IsPermanentDelete),
+				middleware.RequireIfMatch(cfg.Server.RequireIfMatch),
+				bookHandler.DeleteBook,
+			)
+			books.GET("/:id/history", bookHandler.GetBookHistory)
+			books.POST("/:id/restore", bookHandler.RestoreBook)
+		}
+
+		// Audit trail, with snapshots, actors and client IPs
+		api.GET("/audit-events", adminAuth, auditHandler.GetAuditEvents)
+
+		// URL processing endpoints
+		api.POST("/process-url", urlHandler.ProcessURL)
+		api.POST("/process-urls/batch", urlHandler.ProcessURLBatch)
+		api.GET("/url-stats", urlHandler.GetStats)
+
+		// URL processing logs
+		urlLogs := api.Group("/url-logs", adminAuth)
+		{
+			urlLogs.GET("", urlLogHandler.GetLogs)
+			urlLogs.DELETE("", urlLogHandler.EraseLogsByIP)
+			urlLogs.GET("/export", urlLogHandler.ExportLogs)
+			urlLogs.GET("/:id", urlLogHandler.GetLog)
+		}
+
+		// URL rewrite rules
+		urlRules := api.Group("/url-rules")
+		{
+			urlRules.GET("", urlRuleHandler.GetRules)
+			urlRules.POST("", adminAuth, urlRuleHandler.CreateRule)
+			urlRules.GET("/:id", urlRuleHandler.GetRule)
+			urlRules.PUT("/:id", adminAuth, urlRuleHandler.UpdateRule)
+			urlRules.DELETE("/:id", adminAuth, urlRuleHandler.DeleteRule)
+		}
+
+		// Tracking parameter lists
+		trackingParams := api.Group("/tracking-params")
+		{
+			trackingParams.GET("", trackingParamHandler.GetRules)
+			trackingParams.POST("", adminAuth, trackingParamHandler.CreateRule)
+			trackingParams.DELETE("/:id", adminAuth, trackingParamHandler.DeleteRule)
+		}
+
+		// Short links
+		shortLinks := api.Group("/short-links")
+		{
+			shortLinks.POST("", shortLinkHandler.CreateShortLink)
+			shortLinks.GET("/:code/stats", shortLinkHandler.GetShortLinkStats)
+			shortLinks.DELETE("/:code", adminAuth, shortLinkHandler.DeleteShortLink)
+		}
+	}
+
+	// Public short link redirects
+	router.GET("/s/:code", shortLinkHandler.FollowShortLink)
+
+	// Serve during migrations so /livez answers and /readyz reports them
+	server := &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           router,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	// Apply pending migrations; concurrent instances wait for each other
+	if cfg.Database.MigrateOnStart {
+		healthRegistry.SetReady(false, "migrating")
+		applied, err := db.MigrateUp(ctx)
+		if err != nil {
+			logger.WithError(err).Fatal("❌ Migration failed")
+		}
+		for _, migration := range applied {
+			logger.WithField("version", migration.Version).Infof("🔄 Applied migration %s", migration.Name)
+		}
+	}
+
+	// Seed sample data
+	if cfg.Database.SeedOnStart {
+		seeded, err := db.SeedBooks(ctx, database.SampleBooks())
+		if err != nil {
+			logger.WithError(err).Warn("Failed to seed data")
+		} else if seeded > 0 {
+			logger.Infof("🌱 Seeded %d sample books", seeded)
+		}
+	}
+
+	// Jobs stop with their own context once the server has drained
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	scheduler.Start(jobsCtx)
+	healthRegistry.SetReady(true, "")
+
+	logger.WithFields(logrus.Fields{
+		"port":        cfg.Server.Port,
+		"swagger_url": "http://localhost:" + cfg.Server.Port + "/swagger/index.html",
+		"environment": cfg.App.Environment,
+		"tracing":     cfg.Tracing.Exporter,
+		"database":    cfg.Database.Target(),
+	}).Info("🌟 Server running")
+
+	select {
+	case err := <-serveErr:
+		logger.WithError(err).Fatal("❌ Failed to start server")
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Fail readiness first so load balancers stop sending new requests
+	// before the listener closes
+	logger.WithField("drain_period", cfg.Server.DrainPeriod.String()).Info("🛑 Shutting down, draining")
+	healthRegistry.SetReady(false, "draining")
+	time.Sleep(cfg.Server.DrainPeriod)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.WithError(err).Warn("In-flight requests did not finish, closing connections")
+		server.Close()
+	}
+
+	// Jobs get a deadline of their own, so requests that used up the
+	// shutdown timeout do not cut them short
+	cancelJobs()
+	jobsWaitCtx, cancelJobsWait := context.WithTimeout(context.Background(), cfg.Server.JobsShutdownTimeout)
+	defer cancelJobsWait()
+	if err := scheduler.Wait(jobsWaitCtx); err != nil {
+		logger.WithError(err).Warn("Background jobs did not stop in time")
+	}
+
+	// The database closes once the jobs have stopped or been given up on
+	if err := db.Close(); err != nil {
+		logger.WithError(err).Warn("Failed to close database")
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.WithError(err).Warn("Failed to flush traces")
+	}
+
+	logger.Info("👋 Server stopped")
+	return exitOK
+}
+
+// newLogger builds the JSON logger shared by requests and jobs at the
+// configured level, falling back to info for unknown levels
+func newLogger(cfg *config.AppConfig) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(tracing.LogHook{})
+
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		logger.WithField("log_level", cfg.LogLevel).Warn("Unknown log level, using info")
+		level = logrus.InfoLevel
+	}
+	logger.SetLevel(level)
+
+	return logger
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"os"
+	"strings"
+	"time"
+)
+
+const userUsage = `Usage: server user create <username> [--role admin] [--password-stdin] [--json]
+
+Creates a user for the admin endpoints. Without --password-stdin a random
+password is generated and printed once.
+
+Flags:
+`
+
+type createdUser struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	Password  string    `json:"password,omitempty"` // only when generated
+}
+
+// runUser runs a user subcommand; create is the only one
+func runUser(args []string) int {
+	flags := newFlagSet("user", userUsage)
+	out := addOutputFlag(flags)
+	role := flags.String("role", models.RoleAdmin, "role of the user: "+strings.Join(models.Roles, ", "))
+	passwordStdin := flags.Bool("password-stdin", false, "read the password from the first line of standard input")
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return usageExit(err)
+	}
+	if len(positional) != 2 || positional[0] != "create" {
+		flags.Usage()
+		return exitUsage
+	}
+
+	password, generated := "", false
+	if *passwordStdin {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return out.fail(errors.New("no password on standard input"))
+		}
+		password = strings.TrimRight(line, "\r\n")
+	} else {
+		password, generated = generatePassword(), true
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	_, db, err := openDatabase(ctx)
+	if err != nil {
+		return out.fail(err)
+	}
+	defer db.Close()
+
+	user, err := service.NewUserService(db).CreateUser(ctx, positional[1], password, *role)
+	if err != nil {
+		return out.fail(err)
+	}
+
+	result := createdUser{ID: user.ID, Username: user.Username, Role: user.Role, CreatedAt: user.CreatedAt}
+	text := fmt.Sprintf("✅ Created %s user %s\n", user.Role, user.Username)
+	if generated {
+		result.Password = password
+		text += fmt.Sprintf("Password: %s\n", password)
+	}
+	out.result(result, "%s", text)
+	return exitOK
+}
+
+// generatePassword returns a random password of 24 URL-safe characters
+func generatePassword() string {
+	buf := make([]byte, 18)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -24,6 +24,108 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/audit-events": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Get audit events with optional filtering by entity, action, actor, request ID and time range, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by entity type (e.g. book)",
+                        "name": "entity_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by action (create, update, delete, restore)",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.AuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/books": {
             "get": {
                 "description": "Get a list of all books with optional filtering and pagination",
@@ -126,6 +228,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -135,6 +243,76 @@ const docTemplate = `{
                 }
             }
         },
+        "/books/bulk": {
+            "post": {
+                "description": "Apply a batch of create, update and delete operations. In atomic mode (default) all operations succeed or none are applied; in best_effort mode each operation is applied independently. Results are returned in request order. When If-Match is required, updates and deletes without a version fail with 428.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Bulk book operations",
+                "parameters": [
+                    {
+                        "description": "Batch of operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BulkBookResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/books/search": {
             "get": {
                 "description": "Search for books by title, author, or description",
@@ -179,6 +357,49 @@ const docTemplate = `{
                 }
             }
         },
+        "/books/trash": {
+            "get": {
+                "description": "Get books in the trash, most recently deleted first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List deleted books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default 10, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items to skip (default 0)",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.TrashResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/books/{id}": {
             "get": {
                 "description": "Get a single book by its ID",
@@ -199,6 +420,12 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous read",
+                        "name": "If-None-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -206,8 +433,17 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/library-backend_internal_models.BookResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Current version of the book"
+                            }
                         }
                     },
+                    "304": {
+                        "description": "Not modified"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -248,6 +484,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "Updated book information",
                         "name": "book",
@@ -277,6 +519,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -286,7 +546,12 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a book by ID",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Move a book to the trash by ID, or remove it permanently with permanent=true (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -304,6 +569,18 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Permanently delete the book instead of moving it to the trash",
+                        "name": "permanent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the delete is based on",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -319,12 +596,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -332,29 +627,41 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/process-url": {
-            "post": {
-                "description": "Process a URL based on the specified operation (canonical, redirection, or all)",
+            },
+            "patch": {
+                "description": "Partially update a book with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Optional fields can be cleared with null or remove. The patched book is validated like a new book.",
                 "consumes": [
-                    "application/json"
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "url-processing"
+                    "books"
                 ],
-                "summary": "Process URL",
+                "summary": "Patch book",
                 "parameters": [
                     {
-                        "description": "URL processing request",
-                        "name": "request",
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the patch is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch object or JSON Patch operation array",
+                        "name": "patch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/library-backend_internal_models.URLRequest"
+                            "type": "object"
                         }
                     }
                 ],
@@ -362,7 +669,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/library-backend_internal_models.URLResponse"
+                            "$ref": "#/definitions/library-backend_internal_models.BookResponse"
                         }
                     },
                     "400": {
@@ -371,33 +678,324 @@ const docTemplate = `{
                             "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/url-stats": {
-            "get": {
-                "description": "Get statistics about URL processing operations",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "url-processing"
-                ],
-                "summary": "Get URL processing statistics",
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/history": {
+            "get": {
+                "description": "Get every recorded change to a book, oldest first, including changes to deleted books",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get book history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                            "$ref": "#/definitions/library-backend_internal_models.AuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/restore": {
+            "post": {
+                "description": "Move a book out of the trash",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Restore book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.BookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/process-url": {
+            "post": {
+                "description": "Process a URL based on the specified operation (canonical, redirection, normalize, resolve, or all)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Process URL",
+                "parameters": [
+                    {
+                        "description": "URL processing request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/process-urls/batch": {
+            "post": {
+                "description": "Process many URLs concurrently. The body is either a JSON array whose items are URL strings or URL request objects, or a newline-delimited list of URLs (text/plain, text/uri-list or a multipart \"file\" upload; blank lines and lines starting with # are skipped). The operation and canonical_mode query parameters apply to items that do not set their own. The resolve operation, which makes network requests, is accepted for fewer URLs than the others. Results are returned in input order.",
+                "consumes": [
+                    "application/json",
+                    "text/plain",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Process URLs in batch",
+                "parameters": [
+                    {
+                        "enum": [
+                            "redirection",
+                            "canonical",
+                            "normalize",
+                            "resolve",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Default operation",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "strip_all",
+                            "strip_tracking"
+                        ],
+                        "type": "string",
+                        "description": "Default canonical mode",
+                        "name": "canonical_mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "URLs to process",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/library-backend_internal_models.URLRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/short-links": {
+            "post": {
+                "description": "Create a short link with a generated code or a custom alias. The target can first be run through a URL processing operation. redirect_type chooses between 301 and 302 (default) redirects.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Create short link",
+                "parameters": [
+                    {
+                        "description": "Short link definition",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.CreateShortLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ShortLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -408,189 +1006,2088 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "library-backend_internal_models.Book": {
+        },
+        "/short-links/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a short link and its click history (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Delete short link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/short-links/{code}/stats": {
+            "get": {
+                "description": "Get a short link with its click count and the most frequent referrers and user agents",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "short-links"
+                ],
+                "summary": "Get short link statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ShortLinkStatsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracking-params": {
+            "get": {
+                "description": "Get the built-in tracking parameters and the custom allow/deny rules used by canonical_mode=strip_tracking",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "List tracking parameter rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.TrackingParamRulesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Allow or deny a query parameter globally (empty domain) or for a domain such as example.com or *.example.com (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Create tracking parameter rule",
+                "parameters": [
+                    {
+                        "description": "Rule definition",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.TrackingParamRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.TrackingParamRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tracking-params/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a custom tracking parameter rule by ID (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Delete tracking parameter rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Get URL processing logs with optional filters, newest first by default. Pass next_cursor of a page as cursor to get the following page (admin only).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "List URL logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by operation",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only logs at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only logs before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by host of the original URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by client IP, matched in full or hashed form; pass the network address (e.g. 192.0.2.0) to match truncated rows",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "description": "Sort order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to get",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Permanently delete every URL processing log, redirect hop and short link click recorded for an IP address, whether it was stored in full or hashed (admin only). Truncated addresses are shared by a whole network and are not erased. Intended for data-subject erasure requests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Erase URL logs by IP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client IP address",
+                        "name": "ip",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/library-backend_internal_models.URLLogErasure"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-logs/export": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Stream every URL processing log matching the filters as CSV or JSON Lines (admin only)",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Export URL logs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "description": "Export format (default csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by operation",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only logs at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only logs before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by host of the original URL",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by client IP, matched in full or hashed form; pass the network address (e.g. 192.0.2.0) to match truncated rows",
+                        "name": "ip",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "id",
+                            "-id"
+                        ],
+                        "type": "string",
+                        "description": "Sort order (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Exported logs",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-logs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Get a URL processing log by the log_id returned when the URL was processed, with its redirect hops (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Get URL log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Log ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLLogResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-rules": {
+            "get": {
+                "description": "Get every URL rewrite rule in evaluation order (ascending priority)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-rules"
+                ],
+                "summary": "List URL rules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRulesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Create a URL rewrite rule used by the redirection operation (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-rules"
+                ],
+                "summary": "Create URL rule",
+                "parameters": [
+                    {
+                        "description": "Rule definition",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-rules/{id}": {
+            "get": {
+                "description": "Get a single URL rewrite rule by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-rules"
+                ],
+                "summary": "Get URL rule by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Replace a URL rewrite rule by ID (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-rules"
+                ],
+                "summary": "Update URL rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule definition",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.URLRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Delete a URL rewrite rule by ID (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-rules"
+                ],
+                "summary": "Delete URL rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/url-stats": {
+            "get": {
+                "description": "Get statistics about URL processing operations in an optional time range: totals per operation, a time series bucketed by hour, day or week, top original domains, top processed URLs, unique client IPs and the ratio of URLs that were changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "url-processing"
+                ],
+                "summary": "Get URL processing statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only logs at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only logs before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hour",
+                            "day",
+                            "week"
+                        ],
+                        "type": "string",
+                        "description": "Time series bucket size (default day)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per top list (default 10, max 100)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/library-backend_internal_models.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/library-backend_internal_models.URLStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/library-backend_internal_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "library-backend_internal_models.AuditChanges": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/library-backend_internal_models.FieldChange"
+            }
+        },
+        "library-backend_internal_models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "changes": {
+                    "$ref": "#/definitions/library-backend_internal_models.AuditChanges"
+                },
+                "claimed_actor": {
+                    "description": "unverified X-Actor header of an anonymous request",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.AuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.AuditEvent"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.Book": {
+            "type": "object",
+            "required": [
+                "author",
+                "title",
+                "year"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every change for optimistic locking",
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer",
+                    "maximum": 2024,
+                    "minimum": 1000
+                }
+            }
+        },
+        "library-backend_internal_models.BookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.Book"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.BooksResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.Book"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.BulkBookOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.BulkBookRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.BulkBookOperation"
+                    }
+                }
+            }
+        },
+        "library-backend_internal_models.BulkBookResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.BulkBookResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.BulkBookResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.Book"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.ClickCount": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.CreateBookRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "title",
+                "year"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "description": {
+                    "type": "string"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "year": {
+                    "type": "integer",
+                    "maximum": 2024,
+                    "minimum": 1000
+                }
+            }
+        },
+        "library-backend_internal_models.CreateShortLinkRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 3
+                },
+                "canonical_mode": {
+                    "type": "string",
+                    "enum": [
+                        "strip_all",
+                        "strip_tracking"
+                    ]
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string",
+                    "enum": [
+                        "redirection",
+                        "canonical",
+                        "normalize",
+                        "resolve",
+                        "all"
+                    ]
+                },
+                "redirect_type": {
+                    "description": "default 302",
+                    "type": "integer",
+                    "enum": [
+                        301,
+                        302
+                    ]
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.FieldChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {}
+            }
+        },
+        "library-backend_internal_models.RedirectChain": {
+            "type": "object",
+            "properties": {
+                "downgrade": {
+                    "description": "some redirect went from https to http",
+                    "type": "boolean"
+                },
+                "hops": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLRedirectHop"
+                    }
+                },
+                "loop": {
+                    "description": "a redirect pointed back to a visited URL",
+                    "type": "boolean"
+                },
+                "max_hops_exceeded": {
+                    "description": "stopped before reaching a final response",
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.ShortLink": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "custom": {
+                    "description": "code chosen by the creator",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "redirect_type": {
+                    "description": "301 or 302",
+                    "type": "integer"
+                },
+                "target_url": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.ShortLinkResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.ShortLink"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.ShortLinkStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "last_click_at": {
+                    "type": "string"
+                },
+                "link": {
+                    "$ref": "#/definitions/library-backend_internal_models.ShortLink"
+                },
+                "referrers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.ClickCount"
+                    }
+                },
+                "user_agents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.ClickCount"
+                    }
+                }
+            }
+        },
+        "library-backend_internal_models.ShortLinkStatsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.ShortLinkStats"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.StringMap": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
+        "library-backend_internal_models.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.TrackingParamRule": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "exact host or *.example.com",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "param": {
+                    "description": "trailing * matches a prefix",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.TrackingParamRuleRequest": {
+            "type": "object",
+            "required": [
+                "action",
+                "param"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "deny"
+                    ]
+                },
+                "domain": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "param": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "library-backend_internal_models.TrackingParamRuleResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.TrackingParamRule"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.TrackingParamRulesResponse": {
+            "type": "object",
+            "properties": {
+                "builtin": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.TrackingParamRule"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.TrashResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.TrashedBook"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.TrashedBook": {
+            "type": "object",
+            "required": [
+                "author",
+                "title",
+                "year"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isbn": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every change for optimistic locking",
+                    "type": "integer"
+                },
+                "year": {
+                    "type": "integer",
+                    "maximum": 2024,
+                    "minimum": 1000
+                }
+            }
+        },
+        "library-backend_internal_models.URLBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLBatchResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.URLBatchResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.URLResponse"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.URLLogErasure": {
+            "type": "object",
+            "properties": {
+                "process_logs": {
+                    "type": "integer"
+                },
+                "redirect_hops": {
+                    "type": "integer"
+                },
+                "short_link_clicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "library-backend_internal_models.URLLogResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.URLProcessLog"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.URLLogsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLProcessLog"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "description": "absent on the last page",
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "library-backend_internal_models.URLProcessLog": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hops": {
+                    "description": "resolve operation only",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLRedirectHop"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "processed_url": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.URLRedirectHop": {
             "type": "object",
-            "required": [
-                "author",
-                "title",
-                "year"
-            ],
             "properties": {
-                "author": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "downgrade": {
+                    "description": "redirects from https to http",
+                    "type": "boolean"
                 },
-                "created_at": {
+                "error": {
                     "type": "string"
                 },
-                "description": {
+                "location": {
+                    "description": "absolute redirect target",
                     "type": "string"
                 },
-                "id": {
+                "method": {
+                    "type": "string"
+                },
+                "position": {
                     "type": "integer"
                 },
-                "isbn": {
+                "status_code": {
+                    "type": "integer"
+                },
+                "url": {
                     "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.URLRequest": {
+            "type": "object",
+            "required": [
+                "operation",
+                "url"
+            ],
+            "properties": {
+                "canonical_mode": {
+                    "description": "default strip_all",
+                    "type": "string",
+                    "enum": [
+                        "strip_all",
+                        "strip_tracking"
+                    ]
                 },
-                "title": {
+                "operation": {
                     "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                    "enum": [
+                        "redirection",
+                        "canonical",
+                        "normalize",
+                        "resolve",
+                        "all"
+                    ]
                 },
-                "updated_at": {
+                "url": {
                     "type": "string"
-                },
-                "year": {
-                    "type": "integer",
-                    "maximum": 2024,
-                    "minimum": 1000
                 }
             }
         },
-        "library-backend_internal_models.BookResponse": {
+        "library-backend_internal_models.URLResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "$ref": "#/definitions/library-backend_internal_models.Book"
+                "applied_rules": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "message": {
+                "log_id": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "processed_url": {
                     "type": "string"
                 },
+                "redirects": {
+                    "$ref": "#/definitions/library-backend_internal_models.RedirectChain"
+                },
+                "removed_params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
         },
-        "library-backend_internal_models.BooksResponse": {
+        "library-backend_internal_models.URLRule": {
             "type": "object",
             "properties": {
-                "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/library-backend_internal_models.Book"
-                    }
+                "add_query_params": {
+                    "$ref": "#/definitions/library-backend_internal_models.StringMap"
                 },
-                "limit": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "message": {
+                "description": {
                     "type": "string"
                 },
-                "page": {
-                    "type": "integer"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "success": {
+                "final": {
                     "type": "boolean"
                 },
-                "total": {
+                "id": {
+                    "type": "integer"
+                },
+                "lowercase": {
+                    "type": "string"
+                },
+                "match_host": {
+                    "description": "Match conditions - empty conditions match every URL",
+                    "type": "string"
+                },
+                "match_path_prefix": {
+                    "type": "string"
+                },
+                "match_path_regex": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path_rewrite_pattern": {
+                    "type": "string"
+                },
+                "path_rewrite_replace": {
+                    "type": "string"
+                },
+                "priority": {
                     "type": "integer"
+                },
+                "remove_query_params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "set_host": {
+                    "type": "string"
+                },
+                "set_scheme": {
+                    "description": "Actions",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "library-backend_internal_models.CreateBookRequest": {
+        "library-backend_internal_models.URLRuleRequest": {
             "type": "object",
             "required": [
-                "author",
-                "title",
-                "year"
+                "name"
             ],
             "properties": {
-                "author": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "add_query_params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 },
                 "description": {
                     "type": "string"
                 },
-                "isbn": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "final": {
+                    "type": "boolean"
+                },
+                "lowercase": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "host",
+                        "path",
+                        "all"
+                    ]
+                },
+                "match_host": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "match_path_prefix": {
                     "type": "string"
                 },
-                "title": {
+                "match_path_regex": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string",
-                    "maxLength": 255,
+                    "maxLength": 100,
                     "minLength": 1
                 },
-                "year": {
-                    "type": "integer",
-                    "maximum": 2024,
-                    "minimum": 1000
+                "path_rewrite_pattern": {
+                    "type": "string"
+                },
+                "path_rewrite_replace": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "remove_query_params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "set_host": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "set_scheme": {
+                    "type": "string",
+                    "enum": [
+                        "http",
+                        "https"
+                    ]
                 }
             }
         },
-        "library-backend_internal_models.ErrorResponse": {
+        "library-backend_internal_models.URLRuleResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "string"
-                },
-                "details": {
-                    "type": "string"
+                "data": {
+                    "$ref": "#/definitions/library-backend_internal_models.URLRule"
                 },
-                "error": {
+                "message": {
                     "type": "string"
                 },
                 "success": {
                     "type": "boolean"
-                },
-                "timestamp": {
-                    "type": "string"
                 }
             }
         },
-        "library-backend_internal_models.SuccessResponse": {
+        "library-backend_internal_models.URLRulesResponse": {
             "type": "object",
             "properties": {
-                "data": {},
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLRule"
+                    }
+                },
                 "message": {
                     "type": "string"
                 },
                 "success": {
                     "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "library-backend_internal_models.URLRequest": {
+        "library-backend_internal_models.URLStats": {
             "type": "object",
-            "required": [
-                "operation",
-                "url"
-            ],
             "properties": {
-                "operation": {
-                    "type": "string",
-                    "enum": [
-                        "redirection",
-                        "canonical",
-                        "all"
-                    ]
+                "by_operation": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 },
-                "url": {
+                "changed_ratio": {
+                    "type": "number"
+                },
+                "changed_requests": {
+                    "description": "processed URL differs from the original",
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLStatsBucket"
+                    }
+                },
+                "to": {
                     "type": "string"
+                },
+                "top_domains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLStatsCount"
+                    }
+                },
+                "top_processed_urls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/library-backend_internal_models.URLStatsCount"
+                    }
+                },
+                "total_requests": {
+                    "type": "integer"
+                },
+                "unique_client_ips": {
+                    "type": "integer"
                 }
             }
         },
-        "library-backend_internal_models.URLResponse": {
+        "library-backend_internal_models.URLStatsBucket": {
             "type": "object",
             "properties": {
-                "log_id": {
+                "count": {
                     "type": "integer"
                 },
-                "operation": {
-                    "type": "string"
-                },
-                "original_url": {
+                "start": {
                     "type": "string"
+                }
+            }
+        },
+        "library-backend_internal_models.URLStatsCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "processed_url": {
+                "value": {
                     "type": "string"
-                },
-                "success": {
-                    "type": "boolean"
                 }
             }
         },
@@ -652,6 +3149,9 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "request_id": {
+                    "type": "string"
+                },
                 "success": {
                     "type": "boolean"
                 }
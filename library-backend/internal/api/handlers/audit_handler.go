@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service *service.AuditService
+}
+
+func NewAuditHandler(service *service.AuditService) *AuditHandler {
+	return &AuditHandler{service: service}
+}
+
+// GetAuditEvents queries the audit trail across all entities
+// @Summary      Query audit events
+// @Description  Get audit events with optional filtering by entity, action, actor, request ID and time range, newest first
+// @Tags         audit
+// @Accept       json
+// @Produce      json
+// @Param        entity_type  query     string  false  "Filter by entity type (e.g. book)"
+// @Param        entity_id    query     int     false  "Filter by entity ID"
+// @Param        action       query     string  false  "Filter by action (create, update, delete, restore)"
+// @Param        actor        query     string  false  "Filter by actor"
+// @Param        request_id   query     string  false  "Filter by request ID"
+// @Param        from         query     string  false  "Only events at or after this time (RFC 3339)"
+// @Param        to           query     string  false  "Only events before this time (RFC 3339)"
+// @Param        limit        query     int     false  "Number of items per page (default 50, max 500)"
+// @Param        offset       query     int     false  "Number of items to skip (default 0)"
+// @Success      200          {object}  models.AuditEventsResponse
+// @Failure      400          {object}  models.ErrorResponse
+// @Failure      401          {object}  models.ErrorResponse
+// @Failure      500          {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /audit-events [get]
+func (h *AuditHandler) GetAuditEvents(c *gin.Context) {
+	var filter models.AuditFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid query parameters", "INVALID_QUERY", err.Error())
+		return
+	}
+
+	// Set defaults
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+
+	response, err := h.service.QueryEvents(c.Request.Context(), &filter)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch audit events", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BulkBooks applies a batch of book operations
+// @Summary      Bulk book operations
+// @Description  Apply a batch of create, update and delete operations. In atomic mode (default) all operations succeed or none are applied; in best_effort mode each operation is applied independently. Results are returned in request order. When If-Match is required, updates and deletes without a version fail with 428.
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BulkBookRequest  true  "Batch of operations"
+// @Success      200      {object}  models.BulkBookResponse
+// @Failure      400      {object}  models.BulkBookResponse
+// @Failure      404      {object}  models.BulkBookResponse
+// @Failure      409      {object}  models.BulkBookResponse
+// @Failure      412      {object}  models.BulkBookResponse
+// @Failure      428      {object}  models.BulkBookResponse
+// @Failure      500      {object}  models.BulkBookResponse
+// @Router       /books/bulk [post]
+func (h *BookHandler) BulkBooks(c *gin.Context) {
+	var req models.BulkBookRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BulkModeAtomic
+	}
+	atomic := req.Mode == models.BulkModeAtomic
+
+	// Decode and validate every operation before touching the database
+	results := make([]models.BulkBookResult, len(req.Operations))
+	ops := make([]service.BulkOperation, 0, len(req.Operations))
+	opIndexes := make([]int, 0, len(req.Operations))
+	rejected := 0
+	for i, operation := range req.Operations {
+		results[i] = models.BulkBookResult{Index: i, Op: operation.Op, ID: operation.ID}
+
+		// The version stands in for the If-Match header a single write needs
+		if h.server.RequireIfMatch && operation.Op != models.BulkOpCreate && operation.Version == 0 {
+			results[i].Status = http.StatusPreconditionRequired
+			results[i].Error = "Version is required"
+			results[i].Code = "PRECONDITION_REQUIRED"
+			if rejected == 0 {
+				rejected = http.StatusPreconditionRequired
+			}
+			continue
+		}
+
+		op, err := h.decodeBulkOperation(&operation)
+		if err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = "Validation failed"
+			results[i].Code = "VALIDATION_FAILED"
+			results[i].Fields = utils.ValidationFields(err)
+			if len(results[i].Fields) == 0 {
+				results[i].Details = err.Error()
+			}
+			if rejected == 0 {
+				rejected = http.StatusBadRequest
+			}
+			continue
+		}
+
+		ops = append(ops, *op)
+		opIndexes = append(opIndexes, i)
+	}
+
+	if atomic && len(ops) < len(req.Operations) {
+		for _, i := range opIndexes {
+			setBulkError(c, &results[i], service.ErrBulkRolledBack)
+		}
+		sendBulkResponse(c, rejected, req.Mode, results)
+		return
+	}
+
+	outcomes := h.service.BulkApply(requestContext(c), ops, atomic)
+
+	status := http.StatusOK
+	for j, outcome := range outcomes {
+		result := &results[opIndexes[j]]
+		if outcome.Err != nil {
+			setBulkError(c, result, outcome.Err)
+			if atomic && !errors.Is(outcome.Err, service.ErrBulkRolledBack) {
+				status = result.Status
+			}
+			continue
+		}
+
+		result.Status = http.StatusOK
+		if result.Op == models.BulkOpCreate {
+			result.Status = http.StatusCreated
+		}
+		result.Success = true
+		result.Data = outcome.Book
+		if outcome.Book != nil {
+			result.ID = outcome.Book.ID
+		}
+	}
+
+	sendBulkResponse(c, status, req.Mode, results)
+}
+
+// decodeBulkOperation parses and validates the payload of one bulk operation
+func (h *BookHandler) decodeBulkOperation(operation *models.BulkBookOperation) (*service.BulkOperation, error) {
+	op := &service.BulkOperation{
+		Op:      operation.Op,
+		ID:      operation.ID,
+		Version: operation.Version,
+	}
+
+	switch operation.Op {
+	case models.BulkOpCreate:
+		if len(operation.Data) == 0 {
+			return nil, errors.New("data is required for create")
+		}
+		op.Create = &models.CreateBookRequest{}
+		if err := json.Unmarshal(operation.Data, op.Create); err != nil {
+			return nil, err
+		}
+		if err := h.validator.Struct(op.Create); err != nil {
+			return nil, err
+		}
+	case models.BulkOpUpdate:
+		if len(operation.Data) == 0 {
+			return nil, errors.New("data is required for update")
+		}
+		op.Update = &models.UpdateBookRequest{}
+		if err := json.Unmarshal(operation.Data, op.Update); err != nil {
+			return nil, err
+		}
+		if err := h.validator.Struct(op.Update); err != nil {
+			return nil, err
+		}
+	}
+
+	return op, nil
+}
+
+// setBulkError marks result failed with the response for err. Unexpected
+// errors are attached to c, for the error handler to log.
+func setBulkError(c *gin.Context, result *models.BulkBookResult, err error) {
+	status, response := utils.ErrorFor(err, "Operation failed", "DATABASE_ERROR")
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+
+	result.Status = status
+	result.Error = response.Error
+	result.Code = response.Code
+	result.Details = response.Details
+	result.Success = false
+	result.Data = nil
+}
+
+func sendBulkResponse(c *gin.Context, status int, mode string, results []models.BulkBookResult) {
+	response := models.BulkBookResponse{
+		Mode:    mode,
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	response.Success = response.Failed == 0
+
+	c.JSON(status, response)
+}
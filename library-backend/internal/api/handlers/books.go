@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"library-backend/internal/config"
 	"library-backend/internal/models"
 	"library-backend/internal/service"
 	"library-backend/internal/utils"
@@ -13,12 +16,14 @@ import (
 
 type BookHandler struct {
 	service   *service.BookService
+	server    *config.ServerConfig
 	validator *validator.Validate
 }
 
-func NewBookHandler(service *service.BookService) *BookHandler {
+func NewBookHandler(service *service.BookService, server *config.ServerConfig) *BookHandler {
 	return &BookHandler{
 		service:   service,
+		server:    server,
 		validator: validator.New(),
 	}
 }
@@ -54,9 +59,9 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 		filter.Limit = 100
 	}
 
-	response, err := h.service.GetAllBooks(&filter)
+	response, err := h.service.GetAllBooks(c.Request.Context(), &filter)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "Failed to fetch books", "DATABASE_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to fetch books", "DATABASE_ERROR")
 		return
 	}
 
@@ -69,8 +74,11 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int  true  "Book ID"
+// @Param        id             path      int     true   "Book ID"
+// @Param        If-None-Match  header    string  false  "ETag from a previous read"
 // @Success      200  {object}  models.BookResponse
+// @Success      304  "Not modified"
+// @Header       200  {string}  ETag  "Current version of the book"
 // @Failure      400  {object}  models.ErrorResponse
 // @Failure      404  {object}  models.ErrorResponse
 // @Failure      500  {object}  models.ErrorResponse
@@ -83,13 +91,15 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 		return
 	}
 
-	book, err := h.service.GetBookByID(uint(id))
+	book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
 	if err != nil {
-		if err.Error() == "book not found" {
-			utils.SendError(c, http.StatusNotFound, "Book not found", "BOOK_NOT_FOUND")
-			return
-		}
-		utils.SendError(c, http.StatusInternalServerError, "Failed to fetch book", "DATABASE_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to fetch book", "DATABASE_ERROR")
+		return
+	}
+
+	c.Header("ETag", book.ETag())
+	if ifNoneMatch(c, book.ETag()) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -108,6 +118,7 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 // @Param        book  body      models.CreateBookRequest  true  "Book information"
 // @Success      201   {object}  models.BookResponse
 // @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
 // @Failure      500   {object}  models.ErrorResponse
 // @Router       /books [post]
 func (h *BookHandler) CreateBook(c *gin.Context) {
@@ -123,12 +134,13 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 		return
 	}
 
-	book, err := h.service.CreateBook(&req)
+	book, err := h.service.CreateBook(requestContext(c), &req)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "Failed to create book", "DATABASE_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to create book", "DATABASE_ERROR")
 		return
 	}
 
+	c.Header("ETag", book.ETag())
 	c.JSON(http.StatusCreated, models.BookResponse{
 		Success: true,
 		Data:    book,
@@ -142,11 +154,15 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id    path      int                       true  "Book ID"
-// @Param        book  body      models.UpdateBookRequest  true  "Updated book information"
+// @Param        id        path      int                       true   "Book ID"
+// @Param        If-Match  header    string                    false  "ETag the update is based on"
+// @Param        book      body      models.UpdateBookRequest  true   "Updated book information"
 // @Success      200   {object}  models.BookResponse
 // @Failure      400   {object}  models.ValidationErrorResponse
 // @Failure      404   {object}  models.ErrorResponse
+// @Failure      412   {object}  models.ErrorResponse
+// @Failure      428   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
 // @Failure      500   {object}  models.ErrorResponse
 // @Router       /books/{id} [put]
 func (h *BookHandler) UpdateBook(c *gin.Context) {
@@ -157,6 +173,11 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		return
 	}
 
+	expectedVersion, ok := h.ifMatchVersion(c, uint(id), false)
+	if !ok {
+		return
+	}
+
 	var req models.UpdateBookRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
@@ -168,16 +189,13 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		return
 	}
 
-	book, err := h.service.UpdateBook(uint(id), &req)
+	book, err := h.service.UpdateBook(requestContext(c), uint(id), &req, expectedVersion)
 	if err != nil {
-		if err.Error() == "book not found" {
-			utils.SendError(c, http.StatusNotFound, "Book not found", "BOOK_NOT_FOUND")
-			return
-		}
-		utils.SendError(c, http.StatusInternalServerError, "Failed to update book", "DATABASE_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to update book", "DATABASE_ERROR")
 		return
 	}
 
+	c.Header("ETag", book.ETag())
 	c.JSON(http.StatusOK, models.BookResponse{
 		Success: true,
 		Data:    book,
@@ -185,17 +203,115 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 	})
 }
 
+// PatchBook partially updates a book
+// @Summary      Patch book
+// @Description  Partially update a book with a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902). Optional fields can be cleared with null or remove. The patched book is validated like a new book.
+// @Tags         books
+// @Accept       application/merge-patch+json
+// @Accept       application/json-patch+json
+// @Produce      json
+// @Param        id        path      int     true   "Book ID"
+// @Param        If-Match  header    string  false  "ETag the patch is based on"
+// @Param        patch     body      object  true   "Merge patch object or JSON Patch operation array"
+// @Success      200   {object}  models.BookResponse
+// @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      412   {object}  models.ErrorResponse
+// @Failure      415   {object}  models.ErrorResponse
+// @Failure      422   {object}  models.ErrorResponse
+// @Failure      428   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Router       /books/{id} [patch]
+func (h *BookHandler) PatchBook(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid book ID", "INVALID_BOOK_ID", err.Error())
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(c, uint(id), false)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	// Without If-Match the patch applies to whatever version is current, so
+	// retry if another writer gets in between reading and saving the book
+	const maxAttempts = 3
+	for attempt := 1; ; attempt++ {
+		book, err := h.service.GetBookByID(c.Request.Context(), uint(id))
+		if err != nil {
+			utils.SendServiceError(c, err, "Failed to fetch book", "DATABASE_ERROR")
+			return
+		}
+		if expectedVersion != 0 && book.Version != expectedVersion {
+			utils.SendError(c, http.StatusPreconditionFailed, "Book has been modified", "PRECONDITION_FAILED")
+			return
+		}
+
+		patched, err := service.ApplyBookPatch(book, c.ContentType(), patch)
+		if err != nil {
+			utils.SendServiceError(c, err, "Patch could not be applied", "PATCH_FAILED")
+			return
+		}
+
+		if err := h.validator.Struct(patched); err != nil {
+			utils.SendValidationError(c, err)
+			return
+		}
+
+		// Write every editable field so cleared optional fields are saved too
+		req := models.UpdateBookRequest{
+			Title:       &patched.Title,
+			Author:      &patched.Author,
+			Year:        &patched.Year,
+			ISBN:        &patched.ISBN,
+			Description: &patched.Description,
+		}
+
+		updated, err := h.service.UpdateBook(requestContext(c), uint(id), &req, book.Version)
+		if err != nil {
+			if errors.Is(err, service.ErrVersionMismatch) && expectedVersion == 0 && attempt < maxAttempts {
+				continue
+			}
+			utils.SendServiceError(c, err, "Failed to update book", "DATABASE_ERROR")
+			return
+		}
+
+		c.Header("ETag", updated.ETag())
+		c.JSON(http.StatusOK, models.BookResponse{
+			Success: true,
+			Data:    updated,
+			Message: "Book updated successfully",
+		})
+		return
+	}
+}
+
 // DeleteBook deletes a book
 // @Summary      Delete book
-// @Description  Delete a book by ID
+// @Description  Move a book to the trash by ID, or remove it permanently with permanent=true (admin only)
 // @Tags         books
 // @Accept       json
 // @Produce      json
-// @Param        id  path      int  true  "Book ID"
+// @Param        id         path      int     true   "Book ID"
+// @Param        permanent  query     bool    false  "Permanently delete the book instead of moving it to the trash"
+// @Param        If-Match   header    string  false  "ETag the delete is based on"
 // @Success      200 {object}  models.SuccessResponse
 // @Failure      400 {object}  models.ErrorResponse
+// @Failure      401 {object}  models.ErrorResponse
 // @Failure      404 {object}  models.ErrorResponse
+// @Failure      412 {object}  models.ErrorResponse
+// @Failure      428 {object}  models.ErrorResponse
 // @Failure      500 {object}  models.ErrorResponse
+// @Security     BasicAuth
 // @Router       /books/{id} [delete]
 func (h *BookHandler) DeleteBook(c *gin.Context) {
 	idStr := c.Param("id")
@@ -205,18 +321,138 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteBook(uint(id)); err != nil {
-		if err.Error() == "book not found" {
-			utils.SendError(c, http.StatusNotFound, "Book not found", "BOOK_NOT_FOUND")
-			return
-		}
-		utils.SendError(c, http.StatusInternalServerError, "Failed to delete book", "DATABASE_ERROR", err.Error())
+	permanent := IsPermanentDelete(c)
+	expectedVersion, ok := h.ifMatchVersion(c, uint(id), permanent)
+	if !ok {
+		return
+	}
+
+	if permanent {
+		err = h.service.PurgeBook(requestContext(c), uint(id), expectedVersion)
+	} else {
+		err = h.service.DeleteBook(requestContext(c), uint(id), expectedVersion)
+	}
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to delete book", "DATABASE_ERROR")
 		return
 	}
 
+	if permanent {
+		utils.SendSuccess(c, http.StatusOK, "Book permanently deleted", nil)
+		return
+	}
 	utils.SendSuccess(c, http.StatusOK, "Book deleted successfully", nil)
 }
 
+// IsPermanentDelete reports whether a delete request asks to bypass the trash.
+// Such requests must be admin-authenticated.
+func IsPermanentDelete(c *gin.Context) bool {
+	permanent, _ := strconv.ParseBool(c.Query("permanent"))
+	return permanent
+}
+
+// GetTrash lists soft-deleted books
+// @Summary      List deleted books
+// @Description  Get books in the trash, most recently deleted first
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        limit    query     int     false  "Number of items per page (default 10, max 100)"
+// @Param        offset   query     int     false  "Number of items to skip (default 0)"
+// @Success      200      {object}  models.TrashResponse
+// @Failure      500      {object}  models.ErrorResponse
+// @Router       /books/trash [get]
+func (h *BookHandler) GetTrash(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	// Set defaults
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	response, err := h.service.GetDeletedBooks(c.Request.Context(), limit, offset)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch deleted books", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreBook restores a soft-deleted book
+// @Summary      Restore book
+// @Description  Move a book out of the trash
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Book ID"
+// @Success      200  {object}  models.BookResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      409  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /books/{id}/restore [post]
+func (h *BookHandler) RestoreBook(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid book ID", "INVALID_BOOK_ID", err.Error())
+		return
+	}
+
+	book, err := h.service.RestoreBook(requestContext(c), uint(id))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to restore book", "DATABASE_ERROR")
+		return
+	}
+
+	c.Header("ETag", book.ETag())
+	c.JSON(http.StatusOK, models.BookResponse{
+		Success: true,
+		Data:    book,
+		Message: "Book restored successfully",
+	})
+}
+
+// GetBookHistory retrieves the audit trail of a book
+// @Summary      Get book history
+// @Description  Get every recorded change to a book, oldest first, including changes to deleted books
+// @Tags         books
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Book ID"
+// @Success      200  {object}  models.AuditEventsResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /books/{id}/history [get]
+func (h *BookHandler) GetBookHistory(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid book ID", "INVALID_BOOK_ID", err.Error())
+		return
+	}
+
+	events, err := h.service.GetBookHistory(c.Request.Context(), uint(id))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch book history", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditEventsResponse{
+		Success: true,
+		Data:    events,
+		Total:   int64(len(events)),
+	})
+}
+
 // SearchBooks searches for books
 // @Summary      Search books
 // @Description  Search for books by title, author, or description
@@ -235,9 +471,9 @@ func (h *BookHandler) SearchBooks(c *gin.Context) {
 		return
 	}
 
-	books, err := h.service.SearchBooks(query)
+	books, err := h.service.SearchBooks(c.Request.Context(), query)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "Search failed", "DATABASE_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Search failed", "DATABASE_ERROR")
 		return
 	}
 
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"library-backend/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext builds the context passed to services, carrying the acting
+// user and client IP recorded in the audit trail next to the request ID set
+// by the RequestID middleware. Only an authenticated user is an actor; the
+// X-Actor header of other requests is kept apart, as anyone can send it.
+func requestContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+
+	if actor := c.GetString(gin.AuthUserKey); actor != "" {
+		ctx = requestctx.WithActor(ctx, actor)
+	} else if claimed := c.GetHeader("X-Actor"); claimed != "" {
+		ctx = requestctx.WithClaimedActor(ctx, claimed)
+	}
+	ctx = requestctx.WithClientIP(ctx, c.ClientIP())
+
+	return ctx
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"library-backend/internal/config"
+	"library-backend/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+	app      *config.AppConfig
+}
+
+func NewHealthHandler(registry *health.Registry, app *config.AppConfig) *HealthHandler {
+	return &HealthHandler{registry: registry, app: app}
+}
+
+// Livez answers as long as the process can serve HTTP; it checks no
+// dependencies, so a failing database does not get the process restarted
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// Readyz answers 503 while the service should not receive traffic: during
+// startup migrations, while draining on shutdown or when a critical
+// dependency is down
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context(), true)
+	if !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "reason": report.Reason})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// Health is the summary kept for existing monitors; dependency errors are
+// only reported by Details
+func (h *HealthHandler) Health(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context(), true)
+
+	status := http.StatusOK
+	body := gin.H{
+		"status":      "healthy",
+		"app":         h.app.Name,
+		"version":     h.app.Version,
+		"environment": h.app.Environment,
+		"swagger_url": "/swagger/index.html",
+	}
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+		body["status"] = "unhealthy"
+		body["reason"] = report.Reason
+	}
+
+	c.JSON(status, body)
+}
+
+// Details runs every registered check and reports each one with its latency
+// and details. It answers 503 when the report is down.
+func (h *HealthHandler) Details(c *gin.Context) {
+	report := h.registry.Run(c.Request.Context(), false)
+
+	status := http.StatusOK
+	if report.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, gin.H{
+		"status":      report.Status,
+		"ready":       report.Ready,
+		"reason":      report.Reason,
+		"checks":      report.Checks,
+		"app":         h.app.Name,
+		"version":     h.app.Version,
+		"environment": h.app.Environment,
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"library-backend/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ifMatchVersions parses the If-Match header into the book versions a
+// conditional write may be based on. It returns nil when the write is
+// unconditional (no header or "*") and false when no tag in the header can
+// ever match the book's strong ETag.
+func ifMatchVersions(c *gin.Context) ([]uint, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	var versions []uint
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// If-Match uses strong comparison, so weak tags never match
+		if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		version, err := strconv.ParseUint(strings.Trim(candidate, `"`), 10, 32)
+		if err != nil || version == 0 {
+			continue
+		}
+		versions = append(versions, uint(version))
+	}
+	return versions, len(versions) > 0
+}
+
+// ifMatchVersion returns the book version a conditional write of book id
+// expects, or 0 when the write is unconditional. When the If-Match header
+// lists several versions, the current one is looked up to pick the tag that
+// matches it. It replies and returns false when no tag matches.
+func (h *BookHandler) ifMatchVersion(c *gin.Context, id uint, trashed bool) (uint, bool) {
+	versions, ok := ifMatchVersions(c)
+	if !ok {
+		utils.SendError(c, http.StatusPreconditionFailed, "Book has been modified", "PRECONDITION_FAILED")
+		return 0, false
+	}
+	switch len(versions) {
+	case 0:
+		return 0, true
+	case 1:
+		return versions[0], true
+	}
+
+	current, err := h.service.GetBookVersion(c.Request.Context(), id, trashed)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch book", "DATABASE_ERROR")
+		return 0, false
+	}
+	for _, version := range versions {
+		if version == current {
+			return current, true
+		}
+	}
+	utils.SendError(c, http.StatusPreconditionFailed, "Book has been modified", "PRECONDITION_FAILED")
+	return 0, false
+}
+
+// ifNoneMatch reports whether the If-None-Match header matches etag, in which
+// case a read can be answered with 304 Not Modified
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// If-None-Match uses weak comparison
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type ShortLinkHandler struct {
+	service   *service.ShortLinkService
+	validator *validator.Validate
+	cfg       *config.ShortLinkConfig
+}
+
+func NewShortLinkHandler(service *service.ShortLinkService, cfg *config.ShortLinkConfig) *ShortLinkHandler {
+	return &ShortLinkHandler{
+		service:   service,
+		validator: validator.New(),
+		cfg:       cfg,
+	}
+}
+
+// CreateShortLink creates a short link
+// @Summary      Create short link
+// @Description  Create a short link with a generated code or a custom alias. The target can first be run through a URL processing operation. redirect_type chooses between 301 and 302 (default) redirects.
+// @Tags         short-links
+// @Accept       json
+// @Produce      json
+// @Param        link  body      models.CreateShortLinkRequest  true  "Short link definition"
+// @Success      201   {object}  models.ShortLinkResponse
+// @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Failure      422   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Router       /short-links [post]
+func (h *ShortLinkHandler) CreateShortLink(c *gin.Context) {
+	var req models.CreateShortLinkRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	link, err := h.service.CreateLink(requestContext(c), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to create short link", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ShortLinkResponse{
+		Success:  true,
+		Data:     link,
+		ShortURL: h.shortURL(c, link.Code),
+		Message:  "Short link created successfully",
+	})
+}
+
+// GetShortLinkStats retrieves the clicks of a short link
+// @Summary      Get short link statistics
+// @Description  Get a short link with its click count and the most frequent referrers and user agents
+// @Tags         short-links
+// @Accept       json
+// @Produce      json
+// @Param        code  path      string  true  "Short code"
+// @Success      200   {object}  models.ShortLinkStatsResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Router       /short-links/{code}/stats [get]
+func (h *ShortLinkHandler) GetShortLinkStats(c *gin.Context) {
+	stats, err := h.service.GetStats(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to get short link statistics", "STATS_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ShortLinkStatsResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// DeleteShortLink deletes a short link
+// @Summary      Delete short link
+// @Description  Delete a short link and its click history (admin only)
+// @Tags         short-links
+// @Accept       json
+// @Produce      json
+// @Param        code  path      string  true  "Short code"
+// @Success      200   {object}  models.SuccessResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /short-links/{code} [delete]
+func (h *ShortLinkHandler) DeleteShortLink(c *gin.Context) {
+	if err := h.service.DeleteLink(requestContext(c), c.Param("code")); err != nil {
+		utils.SendServiceError(c, err, "Failed to delete short link", "DATABASE_ERROR")
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "Short link deleted successfully", nil)
+}
+
+// FollowShortLink redirects to the target of a short link with its configured
+// 301 or 302 status and counts the click. It is served outside /api/v1 at
+// /s/:code, so it is not part of the Swagger document.
+func (h *ShortLinkHandler) FollowShortLink(c *gin.Context) {
+	link, err := h.service.Follow(c.Request.Context(), c.Param("code"), c.Request.Referer(), c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to follow short link", "DATABASE_ERROR")
+		return
+	}
+
+	// Temporary redirects must reach the server again to be counted
+	if link.RedirectType != http.StatusMovedPermanently {
+		c.Header("Cache-Control", "no-store")
+	}
+	c.Redirect(link.RedirectType, link.TargetURL)
+}
+
+// shortURL builds the public URL of a code from the configured base URL or,
+// when none is set, from the request
+func (h *ShortLinkHandler) shortURL(c *gin.Context, code string) string {
+	base := strings.TrimSuffix(h.cfg.BaseURL, "/")
+	if base == "" {
+		scheme := "http"
+		if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		base = scheme + "://" + c.Request.Host
+	}
+	return base + "/s/" + code
+}
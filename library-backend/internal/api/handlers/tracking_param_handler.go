@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type TrackingParamHandler struct {
+	service   *service.TrackingParamService
+	validator *validator.Validate
+}
+
+func NewTrackingParamHandler(service *service.TrackingParamService) *TrackingParamHandler {
+	return &TrackingParamHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetRules lists the tracking parameter lists
+// @Summary      List tracking parameter rules
+// @Description  Get the built-in tracking parameters and the custom allow/deny rules used by canonical_mode=strip_tracking
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.TrackingParamRulesResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /tracking-params [get]
+func (h *TrackingParamHandler) GetRules(c *gin.Context) {
+	rules, err := h.service.GetRules(c.Request.Context())
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch tracking parameter rules", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TrackingParamRulesResponse{
+		Success: true,
+		Data:    rules,
+		Builtin: models.BuiltinTrackingParams,
+		Total:   int64(len(rules)),
+	})
+}
+
+// CreateRule adds a custom tracking parameter rule
+// @Summary      Create tracking parameter rule
+// @Description  Allow or deny a query parameter globally (empty domain) or for a domain such as example.com or *.example.com (admin only)
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      models.TrackingParamRuleRequest  true  "Rule definition"
+// @Success      201   {object}  models.TrackingParamRuleResponse
+// @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /tracking-params [post]
+func (h *TrackingParamHandler) CreateRule(c *gin.Context) {
+	var req models.TrackingParamRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	rule, err := h.service.CreateRule(requestContext(c), &req)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to create tracking parameter rule", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.TrackingParamRuleResponse{
+		Success: true,
+		Data:    rule,
+		Message: "Tracking parameter rule created successfully",
+	})
+}
+
+// DeleteRule removes a custom tracking parameter rule
+// @Summary      Delete tracking parameter rule
+// @Description  Delete a custom tracking parameter rule by ID (admin only)
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Param        id  path      int  true  "Rule ID"
+// @Success      200 {object}  models.SuccessResponse
+// @Failure      400 {object}  models.ErrorResponse
+// @Failure      401 {object}  models.ErrorResponse
+// @Failure      404 {object}  models.ErrorResponse
+// @Failure      500 {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /tracking-params/{id} [delete]
+func (h *TrackingParamHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid rule ID", "INVALID_RULE_ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteRule(requestContext(c), uint(id)); err != nil {
+		utils.SendServiceError(c, err, "Failed to delete tracking parameter rule", "DATABASE_ERROR")
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "Tracking parameter rule deleted successfully", nil)
+}
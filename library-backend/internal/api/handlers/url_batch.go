@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"library-backend/internal/models"
+	"library-backend/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Longest line accepted in a newline-delimited upload
+const maxBatchLineLength = 64 * 1024
+
+var errTooManyURLs = errors.New("too many urls")
+
+// ProcessURLBatch processes a list of URLs
+// @Summary      Process URLs in batch
+// @Description  Process many URLs concurrently. The body is either a JSON array whose items are URL strings or URL request objects, or a newline-delimited list of URLs (text/plain, text/uri-list or a multipart "file" upload; blank lines and lines starting with # are skipped). The operation and canonical_mode query parameters apply to items that do not set their own. The resolve operation, which makes network requests, is accepted for fewer URLs than the others. Results are returned in input order.
+// @Tags         url-processing
+// @Accept       json,plain,mpfd
+// @Produce      json
+// @Param        operation       query     string              false  "Default operation"  Enums(redirection, canonical, normalize, resolve, all)
+// @Param        canonical_mode  query     string              false  "Default canonical mode"  Enums(strip_all, strip_tracking)
+// @Param        request         body      []models.URLRequest  true   "URLs to process"
+// @Success      200             {object}  models.URLBatchResponse
+// @Failure      400             {object}  models.ErrorResponse
+// @Failure      413             {object}  models.ErrorResponse
+// @Router       /process-urls/batch [post]
+func (h *URLHandler) ProcessURLBatch(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.batch.MaxBodyBytes)
+
+	requests, err := h.readBatch(c)
+	if err != nil {
+		if errors.Is(err, errTooManyURLs) {
+			utils.SendError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("A batch may contain at most %d URLs", h.batch.MaxURLs), "TOO_MANY_URLS")
+			return
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.SendError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("A batch body may be at most %d bytes", tooLarge.Limit), "BODY_TOO_LARGE")
+			return
+		}
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if len(requests) == 0 {
+		utils.SendError(c, http.StatusBadRequest, "No URLs to process", "EMPTY_BATCH")
+		return
+	}
+
+	resolves := 0
+	for i := range requests {
+		if requests[i].Operation == "" {
+			requests[i].Operation = c.Query("operation")
+		}
+		if requests[i].CanonicalMode == "" {
+			requests[i].CanonicalMode = c.Query("canonical_mode")
+		}
+		if requests[i].Operation == "resolve" {
+			resolves++
+		}
+	}
+
+	// Each resolve may take the whole resolver timeout, so far fewer of them
+	// fit in the write timeout than of the offline operations
+	if resolves > h.batch.MaxResolveURLs {
+		utils.SendError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("A batch may resolve at most %d URLs", h.batch.MaxResolveURLs), "TOO_MANY_RESOLVES")
+		return
+	}
+
+	// Validate every URL up front; only the valid ones are processed
+	results := make([]models.URLBatchResult, len(requests))
+	valid := make([]models.URLRequest, 0, len(requests))
+	validIndexes := make([]int, 0, len(requests))
+	for i := range requests {
+		results[i] = models.URLBatchResult{Index: i, URL: requests[i].URL}
+
+		if err := h.validator.Struct(&requests[i]); err != nil {
+			results[i].Error = "Validation failed"
+			results[i].Code = "VALIDATION_FAILED"
+			results[i].Fields = utils.ValidationFields(err)
+			if len(results[i].Fields) == 0 {
+				results[i].Details = err.Error()
+			}
+			continue
+		}
+
+		valid = append(valid, requests[i])
+		validIndexes = append(validIndexes, i)
+	}
+
+	outcomes := h.service.ProcessURLs(c.Request.Context(), valid, h.batch.Workers, c.ClientIP(), c.GetHeader("User-Agent"))
+
+	response := models.URLBatchResponse{Total: len(results)}
+	for j, outcome := range outcomes {
+		result := &results[validIndexes[j]]
+		if outcome.Err != nil {
+			setBatchError(c, result, outcome.Err)
+			continue
+		}
+		result.Success = true
+		result.Data = outcome.Response
+	}
+	for _, result := range results {
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	response.Success = response.Failed == 0
+	response.Results = results
+
+	c.JSON(http.StatusOK, response)
+}
+
+// readBatch decodes the request body into URL requests according to its
+// content type
+func (h *URLHandler) readBatch(c *gin.Context) ([]models.URLRequest, error) {
+	switch c.ContentType() {
+	case "text/plain", "text/uri-list":
+		return h.readURLLines(c.Request.Body)
+	case "multipart/form-data":
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return h.readURLLines(file)
+	default:
+		return h.readURLArray(c.Request.Body)
+	}
+}
+
+// readURLArray decodes a JSON array of URL strings or URL request objects.
+// Items are decoded one at a time, so a batch over the limit is refused
+// without reading the rest of it.
+func (h *URLHandler) readURLArray(r io.Reader) ([]models.URLRequest, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil {
+		return nil, err
+	} else if token != json.Delim('[') {
+		return nil, errors.New("body must be a JSON array")
+	}
+
+	var requests []models.URLRequest
+	for i := 0; decoder.More(); i++ {
+		if i == h.batch.MaxURLs {
+			return nil, errTooManyURLs
+		}
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		var request models.URLRequest
+		if len(item) > 0 && item[0] == '"' {
+			if err := json.Unmarshal(item, &request.URL); err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+		} else if err := json.Unmarshal(item, &request); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		requests = append(requests, request)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// readURLLines reads one URL per line, skipping blank lines and # comments
+func (h *URLHandler) readURLLines(r io.Reader) ([]models.URLRequest, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxBatchLineLength)
+
+	var requests []models.URLRequest
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if len(requests) == h.batch.MaxURLs {
+			return nil, errTooManyURLs
+		}
+		requests = append(requests, models.URLRequest{URL: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// setBatchError marks result failed with the response for err. Unexpected
+// errors are attached to c, for the error handler to log.
+func setBatchError(c *gin.Context, result *models.URLBatchResult, err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		result.Error = "Request cancelled before the URL was processed"
+		result.Code = "CANCELLED"
+		result.Details = err.Error()
+		return
+	}
+
+	status, response := utils.ErrorFor(err, "Failed to process URL", "PROCESSING_ERROR")
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+
+	result.Error = response.Error
+	result.Code = response.Code
+	result.Details = response.Details
+}
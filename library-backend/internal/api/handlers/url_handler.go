@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"library-backend/internal/config"
 	"library-backend/internal/models"
 	"library-backend/internal/service"
 	"library-backend/internal/utils"
@@ -13,24 +14,27 @@ import (
 type URLHandler struct {
 	service   *service.URLService
 	validator *validator.Validate
+	batch     *config.URLBatchConfig
 }
 
-func NewURLHandler(service *service.URLService) *URLHandler {
+func NewURLHandler(service *service.URLService, batch *config.URLBatchConfig) *URLHandler {
 	return &URLHandler{
 		service:   service,
 		validator: validator.New(),
+		batch:     batch,
 	}
 }
 
 // ProcessURL processes a URL based on the specified operation
 // @Summary      Process URL
-// @Description  Process a URL based on the specified operation (canonical, redirection, or all)
+// @Description  Process a URL based on the specified operation (canonical, redirection, normalize, resolve, or all)
 // @Tags         url-processing
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.URLRequest  true  "URL processing request"
 // @Success      200      {object}  models.URLResponse
 // @Failure      400      {object}  models.ValidationErrorResponse
+// @Failure      422      {object}  models.ErrorResponse
 // @Failure      500      {object}  models.ErrorResponse
 // @Router       /process-url [post]
 func (h *URLHandler) ProcessURL(c *gin.Context) {
@@ -50,9 +54,9 @@ func (h *URLHandler) ProcessURL(c *gin.Context) {
 	clientIP := c.ClientIP()
 	userAgent := c.GetHeader("User-Agent")
 
-	response, err := h.service.ProcessURL(&req, clientIP, userAgent)
+	response, err := h.service.ProcessURL(c.Request.Context(), &req, clientIP, userAgent)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "Failed to process URL", "PROCESSING_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to process URL", "PROCESSING_ERROR")
 		return
 	}
 
@@ -61,17 +65,50 @@ func (h *URLHandler) ProcessURL(c *gin.Context) {
 
 // GetStats retrieves URL processing statistics
 // @Summary      Get URL processing statistics
-// @Description  Get statistics about URL processing operations
+// @Description  Get statistics about URL processing operations in an optional time range: totals per operation, a time series bucketed by hour, day or week, top original domains, top processed URLs, unique client IPs and the ratio of URLs that were changed
 // @Tags         url-processing
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  models.SuccessResponse
-// @Failure      500  {object}  models.ErrorResponse
+// @Param        from      query     string  false  "Only logs at or after this time (RFC 3339)"
+// @Param        to        query     string  false  "Only logs before this time (RFC 3339)"
+// @Param        interval  query     string  false  "Time series bucket size (default day)"  Enums(hour, day, week)
+// @Param        top       query     int     false  "Entries per top list (default 10, max 100)"
+// @Success      200       {object}  models.SuccessResponse{data=models.URLStats}
+// @Failure      400       {object}  models.ErrorResponse
+// @Failure      500       {object}  models.ErrorResponse
 // @Router       /url-stats [get]
 func (h *URLHandler) GetStats(c *gin.Context) {
-	stats, err := h.service.GetProcessingStats()
+	var filter models.URLStatsFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid query parameters", "INVALID_QUERY", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		utils.SendError(c, http.StatusBadRequest, "from must be before to", "INVALID_QUERY")
+		return
+	}
+
+	// Set defaults
+	if filter.Interval == "" {
+		filter.Interval = models.StatsIntervalDay
+	}
+	if filter.Top <= 0 {
+		filter.Top = 10
+	}
+	if filter.Top > 100 {
+		filter.Top = 100
+	}
+
+	stats, err := h.service.GetProcessingStats(c.Request.Context(), &filter)
 	if err != nil {
-		utils.SendError(c, http.StatusInternalServerError, "Failed to get statistics", "STATS_ERROR", err.Error())
+		utils.SendServiceError(c, err, "Failed to get statistics", "STATS_ERROR")
 		return
 	}
 
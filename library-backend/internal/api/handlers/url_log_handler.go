@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Rows written between flushes of an export to the client
+const exportFlushEvery = 500
+
+var urlLogCSVHeader = []string{"id", "created_at", "operation", "original_url", "processed_url", "ip_address", "user_agent"}
+
+type URLLogHandler struct {
+	service   *service.URLLogService
+	server    *config.ServerConfig
+	validator *validator.Validate
+}
+
+func NewURLLogHandler(service *service.URLLogService, server *config.ServerConfig) *URLLogHandler {
+	return &URLLogHandler{
+		service:   service,
+		server:    server,
+		validator: validator.New(),
+	}
+}
+
+// EraseLogsByIP erases the logs of a client IP address
+// @Summary      Erase URL logs by IP
+// @Description  Permanently delete every URL processing log, redirect hop and short link click recorded for an IP address, whether it was stored in full or hashed (admin only). Truncated addresses are shared by a whole network and are not erased. Intended for data-subject erasure requests.
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Param        ip  query     string  true  "Client IP address"
+// @Success      200 {object}  models.SuccessResponse{data=models.URLLogErasure}
+// @Failure      400 {object}  models.ErrorResponse
+// @Failure      401 {object}  models.ErrorResponse
+// @Failure      500 {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-logs [delete]
+func (h *URLLogHandler) EraseLogsByIP(c *gin.Context) {
+	ip := c.Query("ip")
+	if net.ParseIP(ip) == nil {
+		utils.SendError(c, http.StatusBadRequest, "A valid ip query parameter is required", "INVALID_IP")
+		return
+	}
+
+	erasure, err := h.service.EraseByIP(requestContext(c), ip)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to erase URL logs", "DATABASE_ERROR")
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "URL logs erased successfully", erasure)
+}
+
+// GetLogs lists URL processing logs
+// @Summary      List URL logs
+// @Description  Get URL processing logs with optional filters, newest first by default. Pass next_cursor of a page as cursor to get the following page (admin only).
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Param        operation  query     string  false  "Filter by operation"
+// @Param        from       query     string  false  "Only logs at or after this time (RFC 3339)"
+// @Param        to         query     string  false  "Only logs before this time (RFC 3339)"
+// @Param        domain     query     string  false  "Filter by host of the original URL"
+// @Param        ip         query     string  false  "Filter by client IP, matched in full or hashed form; pass the network address (e.g. 192.0.2.0) to match truncated rows"
+// @Param        sort       query     string  false  "Sort order (default -created_at)"  Enums(created_at, -created_at, id, -id)
+// @Param        cursor     query     string  false  "Cursor of the page to get"
+// @Param        limit      query     int     false  "Number of items per page (default 50, max 500)"
+// @Success      200        {object}  models.URLLogsResponse
+// @Failure      400        {object}  models.ErrorResponse
+// @Failure      401        {object}  models.ErrorResponse
+// @Failure      500        {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-logs [get]
+func (h *URLLogHandler) GetLogs(c *gin.Context) {
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+
+	// Set defaults
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+
+	response, err := h.service.ListLogs(c.Request.Context(), filter)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch URL logs", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetLog retrieves a URL processing log
+// @Summary      Get URL log
+// @Description  Get a URL processing log by the log_id returned when the URL was processed, with its redirect hops (admin only)
+// @Tags         url-processing
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Log ID"
+// @Success      200  {object}  models.URLLogResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      401  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-logs/{id} [get]
+func (h *URLLogHandler) GetLog(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid log ID", "INVALID_LOG_ID", err.Error())
+		return
+	}
+
+	log, err := h.service.GetLog(c.Request.Context(), uint(id))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch URL log", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.URLLogResponse{
+		Success: true,
+		Data:    log,
+	})
+}
+
+// ExportLogs streams URL processing logs
+// @Summary      Export URL logs
+// @Description  Stream every URL processing log matching the filters as CSV or JSON Lines (admin only)
+// @Tags         url-processing
+// @Produce      text/csv,application/x-ndjson
+// @Param        format     query     string  false  "Export format (default csv)"  Enums(csv, jsonl)
+// @Param        operation  query     string  false  "Filter by operation"
+// @Param        from       query     string  false  "Only logs at or after this time (RFC 3339)"
+// @Param        to         query     string  false  "Only logs before this time (RFC 3339)"
+// @Param        domain     query     string  false  "Filter by host of the original URL"
+// @Param        ip         query     string  false  "Filter by client IP, matched in full or hashed form; pass the network address (e.g. 192.0.2.0) to match truncated rows"
+// @Param        sort       query     string  false  "Sort order (default -created_at)"  Enums(created_at, -created_at, id, -id)
+// @Success      200        {string}  string  "Exported logs"
+// @Failure      400        {object}  models.ErrorResponse
+// @Failure      401        {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-logs/export [get]
+func (h *URLLogHandler) ExportLogs(c *gin.Context) {
+	filter, ok := h.bindFilter(c)
+	if !ok {
+		return
+	}
+
+	// The server write timeout would cut a long export off mid-file behind
+	// a 200. Every flush gets a full timeout instead, which still ends the
+	// export to a client that stopped reading.
+	controller := http.NewResponseController(c.Writer)
+	extendDeadline := func() error {
+		if h.server.WriteTimeout <= 0 {
+			return nil
+		}
+		return controller.SetWriteDeadline(time.Now().Add(h.server.WriteTimeout))
+	}
+	if err := extendDeadline(); err != nil {
+		c.Error(err)
+	}
+
+	var write func(*models.URLProcessLog) error
+	var flush func() error
+
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		writer := csv.NewWriter(c.Writer)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="url-logs.csv"`)
+		if err := writer.Write(urlLogCSVHeader); err != nil {
+			return
+		}
+		write = func(log *models.URLProcessLog) error {
+			return writer.Write([]string{
+				strconv.FormatUint(uint64(log.ID), 10),
+				log.CreatedAt.UTC().Format(time.RFC3339Nano),
+				log.Operation,
+				log.OriginalURL,
+				log.ProcessedURL,
+				log.IPAddress,
+				log.UserAgent,
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "jsonl":
+		encoder := json.NewEncoder(c.Writer)
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="url-logs.jsonl"`)
+		write = func(log *models.URLProcessLog) error {
+			return encoder.Encode(log)
+		}
+		flush = func() error { return nil }
+	default:
+		utils.SendError(c, http.StatusBadRequest, "format must be csv or jsonl", "INVALID_FORMAT")
+		return
+	}
+
+	c.Status(http.StatusOK)
+
+	// Rows are flushed to the client batch by batch; once the body has
+	// started an error can only end the stream early
+	rows := 0
+	err := h.service.ExportLogs(c.Request.Context(), filter, func(log *models.URLProcessLog) error {
+		if err := write(log); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+			return extendDeadline()
+		}
+		return nil
+	})
+	if err != nil {
+		c.Error(err)
+	}
+	flush()
+	c.Writer.Flush()
+}
+
+// bindFilter reads the log filters of the query string, replying with an
+// error when they are invalid
+func (h *URLLogHandler) bindFilter(c *gin.Context) (*models.URLLogFilter, bool) {
+	var filter models.URLLogFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid query parameters", "INVALID_QUERY", err.Error())
+		return nil, false
+	}
+
+	if err := h.validator.Struct(&filter); err != nil {
+		utils.SendValidationError(c, err)
+		return nil, false
+	}
+
+	return &filter, true
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type URLRuleHandler struct {
+	service   *service.URLRuleService
+	validator *validator.Validate
+}
+
+func NewURLRuleHandler(service *service.URLRuleService) *URLRuleHandler {
+	return &URLRuleHandler{
+		service:   service,
+		validator: validator.New(),
+	}
+}
+
+// GetRules lists all URL rewrite rules
+// @Summary      List URL rules
+// @Description  Get every URL rewrite rule in evaluation order (ascending priority)
+// @Tags         url-rules
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.URLRulesResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /url-rules [get]
+func (h *URLRuleHandler) GetRules(c *gin.Context) {
+	rules, err := h.service.GetRules(c.Request.Context())
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch URL rules", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.URLRulesResponse{
+		Success: true,
+		Data:    rules,
+		Total:   int64(len(rules)),
+	})
+}
+
+// GetRule retrieves a single URL rewrite rule
+// @Summary      Get URL rule by ID
+// @Description  Get a single URL rewrite rule by its ID
+// @Tags         url-rules
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Rule ID"
+// @Success      200  {object}  models.URLRuleResponse
+// @Failure      400  {object}  models.ErrorResponse
+// @Failure      404  {object}  models.ErrorResponse
+// @Failure      500  {object}  models.ErrorResponse
+// @Router       /url-rules/{id} [get]
+func (h *URLRuleHandler) GetRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid rule ID", "INVALID_RULE_ID", err.Error())
+		return
+	}
+
+	rule, err := h.service.GetRuleByID(c.Request.Context(), uint(id))
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to fetch URL rule", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.URLRuleResponse{
+		Success: true,
+		Data:    rule,
+	})
+}
+
+// CreateRule creates a URL rewrite rule
+// @Summary      Create URL rule
+// @Description  Create a URL rewrite rule used by the redirection operation (admin only)
+// @Tags         url-rules
+// @Accept       json
+// @Produce      json
+// @Param        rule  body      models.URLRuleRequest  true  "Rule definition"
+// @Success      201   {object}  models.URLRuleResponse
+// @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-rules [post]
+func (h *URLRuleHandler) CreateRule(c *gin.Context) {
+	var req models.URLRuleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	rule, err := h.service.CreateRule(requestContext(c), &req)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to create URL rule", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.URLRuleResponse{
+		Success: true,
+		Data:    rule,
+		Message: "URL rule created successfully",
+	})
+}
+
+// UpdateRule replaces a URL rewrite rule
+// @Summary      Update URL rule
+// @Description  Replace a URL rewrite rule by ID (admin only)
+// @Tags         url-rules
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                    true  "Rule ID"
+// @Param        rule  body      models.URLRuleRequest  true  "Rule definition"
+// @Success      200   {object}  models.URLRuleResponse
+// @Failure      400   {object}  models.ValidationErrorResponse
+// @Failure      401   {object}  models.ErrorResponse
+// @Failure      404   {object}  models.ErrorResponse
+// @Failure      409   {object}  models.ErrorResponse
+// @Failure      500   {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-rules/{id} [put]
+func (h *URLRuleHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid rule ID", "INVALID_RULE_ID", err.Error())
+		return
+	}
+
+	var req models.URLRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid request format", "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		utils.SendValidationError(c, err)
+		return
+	}
+
+	rule, err := h.service.UpdateRule(requestContext(c), uint(id), &req)
+	if err != nil {
+		utils.SendServiceError(c, err, "Failed to update URL rule", "DATABASE_ERROR")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.URLRuleResponse{
+		Success: true,
+		Data:    rule,
+		Message: "URL rule updated successfully",
+	})
+}
+
+// DeleteRule deletes a URL rewrite rule
+// @Summary      Delete URL rule
+// @Description  Delete a URL rewrite rule by ID (admin only)
+// @Tags         url-rules
+// @Accept       json
+// @Produce      json
+// @Param        id  path      int  true  "Rule ID"
+// @Success      200 {object}  models.SuccessResponse
+// @Failure      400 {object}  models.ErrorResponse
+// @Failure      401 {object}  models.ErrorResponse
+// @Failure      404 {object}  models.ErrorResponse
+// @Failure      500 {object}  models.ErrorResponse
+// @Security     BasicAuth
+// @Router       /url-rules/{id} [delete]
+func (h *URLRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.SendError(c, http.StatusBadRequest, "Invalid rule ID", "INVALID_RULE_ID", err.Error())
+		return
+	}
+
+	if err := h.service.DeleteRule(requestContext(c), uint(id)); err != nil {
+		utils.SendServiceError(c, err, "Failed to delete URL rule", "DATABASE_ERROR")
+		return
+	}
+
+	utils.SendSuccess(c, http.StatusOK, "URL rule deleted successfully", nil)
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth requires HTTP Basic credentials of the configured admin account
+// or of an admin user, and stores the username under gin.AuthUserKey
+func AdminAuth(cfg *config.AdminConfig, users *service.UserService) gin.HandlerFunc {
+	return AdminAuthWhen(cfg, users, func(*gin.Context) bool { return true })
+}
+
+// AdminAuthWhen applies AdminAuth only to requests for which cond returns
+// true, for endpoints where a query flag escalates the operation
+func AdminAuthWhen(cfg *config.AdminConfig, users *service.UserService, cond func(c *gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cond(c) {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		username, password, ok := c.Request.BasicAuth()
+		if ok {
+			// The configured account needs no database, so it keeps working
+			// while the database is down
+			if cfg.Password != "" &&
+				subtle.ConstantTimeCompare([]byte(username), []byte(cfg.Username)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(password), []byte(cfg.Password)) == 1 {
+				c.Set(gin.AuthUserKey, username)
+				c.Next()
+				return
+			}
+
+			user, err := users.Authenticate(ctx, username, password)
+			switch {
+			case err == nil && user.Role == models.RoleAdmin:
+				c.Set(gin.AuthUserKey, user.Username)
+				c.Next()
+				return
+			case err == nil:
+				utils.SendError(c, http.StatusForbidden, "Admin role required", "FORBIDDEN")
+				c.Abort()
+				return
+			case !errors.Is(err, service.ErrInvalidCredentials):
+				utils.SendServiceError(c, err, "Failed to check credentials", "DATABASE_ERROR")
+				c.Abort()
+				return
+			}
+		}
+
+		if cfg.Password == "" {
+			if hasAdmins, err := users.HasAdmins(ctx); err == nil && !hasAdmins {
+				utils.SendError(c, http.StatusForbidden, "Admin access is not configured", "ADMIN_DISABLED")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Header("WWW-Authenticate", `Basic realm="library-admin"`)
+		utils.SendError(c, http.StatusUnauthorized, "Admin credentials required", "UNAUTHORIZED")
+		c.Abort()
+	}
+}
@@ -1,15 +1,15 @@
 package middleware
 
 import (
-	"library-backend/internal/models"
-	"net/http"
+	"library-backend/internal/requestctx"
+	"library-backend/internal/utils"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
+func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
@@ -17,21 +17,22 @@ func ErrorHandler(logger *logrus.Logger) gin.HandlerFunc {
 		if len(c.Errors) > 0 {
 			err := c.Errors.Last()
 
-			logger.WithFields(logrus.Fields{
+			requestctx.Logger(c.Request.Context()).WithFields(logrus.Fields{
 				"method": c.Request.Method,
 				"path":   c.Request.URL.Path,
 				"error":  err.Error(),
 			}).Error("Request error")
 
-			errorResp := &models.ErrorResponse{
-				Success:   false,
-				Error:     "An error occurred",
-				Code:      "REQUEST_ERROR",
-				Details:   err.Error(),
-				Timestamp: time.Now().Format(time.RFC3339),
+			// A response that has started, such as a stream, cannot be replaced
+			if c.Writer.Written() {
+				return
 			}
 
-			c.JSON(http.StatusInternalServerError, errorResp)
+			status, errorResp := utils.ErrorFor(err.Err, "An error occurred", "REQUEST_ERROR")
+			errorResp.RequestID = requestctx.RequestID(c.Request.Context())
+			errorResp.Timestamp = time.Now().Format(time.RFC3339)
+
+			c.JSON(status, errorResp)
 		}
 	}
 }
@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	"library-backend/internal/requestctx"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
-func Logger(logger *logrus.Logger) gin.HandlerFunc {
+// Logger writes an access log line for every request with the request-scoped
+// logger set up by RequestID
+func Logger() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		startTime := time.Now()
 
@@ -16,7 +19,7 @@ func Logger(logger *logrus.Logger) gin.HandlerFunc {
 		endTime := time.Now()
 		latency := endTime.Sub(startTime)
 
-		logger.WithFields(logrus.Fields{
+		requestctx.Logger(c.Request.Context()).WithFields(logrus.Fields{
 			"status":     c.Writer.Status(),
 			"method":     c.Request.Method,
 			"path":       c.Request.URL.Path,
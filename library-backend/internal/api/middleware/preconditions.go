@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"library-backend/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIfMatch rejects writes without an If-Match header with 428 when
+// enabled, so clients must prove which version they are changing
+func RequireIfMatch(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if enabled && c.GetHeader("If-Match") == "" {
+			utils.SendError(c, http.StatusPreconditionRequired, "If-Match header is required", "PRECONDITION_REQUIRED")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,30 +1,65 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database DatabaseConfig `json:"database"`
-	Server   ServerConfig   `json:"server"`
-	App      AppConfig      `json:"app"`
+	Database   DatabaseConfig  `json:"database"`
+	Server     ServerConfig    `json:"server"`
+	App        AppConfig       `json:"app"`
+	Admin      AdminConfig     `json:"admin"`
+	Trash      TrashConfig     `json:"trash"`
+	URLBatch   URLBatchConfig  `json:"url_batch"`
+	Resolver   ResolverConfig  `json:"resolver"`
+	ShortLinks ShortLinkConfig `json:"short_links"`
+	URLLogs    URLLogConfig    `json:"url_logs"`
+	URLPolicy  URLPolicyConfig `json:"url_policy"`
+	Tracing    TracingConfig   `json:"tracing"`
+
+	envErrors []error // variables Load could not parse, reported by Validate
 }
 
 type DatabaseConfig struct {
+	Driver   string `json:"driver"` // postgres, sqlite
+	Path     string `json:"path"`   // database file for sqlite
 	Host     string `json:"host"`
 	Port     string `json:"port"`
 	User     string `json:"user"`
-	Password string `json:"password"`
+	Password string `json:"-"`
 	DBName   string `json:"dbname"`
 	SSLMode  string `json:"sslmode"`
 	TimeZone string `json:"timezone"`
+
+	MigrateOnStart bool `json:"migrate_on_start"` // apply pending migrations when the server starts
+	SeedOnStart    bool `json:"seed_on_start"`    // insert the sample books into an empty database
+}
+
+// Target describes the database for logs, without credentials
+func (c *DatabaseConfig) Target() string {
+	if c.Driver == "sqlite" {
+		return "sqlite:" + c.Path
+	}
+	return c.Host + ":" + c.Port + "/" + c.DBName
 }
 
 type ServerConfig struct {
-	Port string `json:"port"`
-	Mode string `json:"mode"` // debug, release, test
+	Port                string        `json:"port"`
+	Mode                string        `json:"mode"`             // debug, release, test
+	RequireIfMatch      bool          `json:"require_if_match"` // reject unconditional updates and deletes
+	ReadHeaderTimeout   time.Duration `json:"read_header_timeout"`
+	ReadTimeout         time.Duration `json:"read_timeout"`          // including the body, e.g. batch uploads
+	WriteTimeout        time.Duration `json:"write_timeout"`         // bounds batches too, and each flush of an export; 0 for none
+	IdleTimeout         time.Duration `json:"idle_timeout"`          // keep-alive connections
+	DrainPeriod         time.Duration `json:"drain_period"`          // readiness fails this long before the listener closes
+	ShutdownTimeout     time.Duration `json:"shutdown_timeout"`      // for in-flight requests to finish
+	JobsShutdownTimeout time.Duration `json:"jobs_shutdown_timeout"` // then for background jobs to stop
 }
 
 type AppConfig struct {
@@ -34,12 +69,86 @@ type AppConfig struct {
 	LogLevel    string `json:"log_level"`
 }
 
+// AdminConfig holds the HTTP Basic credentials of the built-in account for
+// administrative endpoints. The account is disabled while Password is empty;
+// admin users stored in the database are accepted either way.
+type AdminConfig struct {
+	Username string `json:"username"`
+	Password string `json:"-"`
+}
+
+// TrashConfig controls how long soft-deleted books are kept before being purged
+type TrashConfig struct {
+	RetentionDays int           `json:"retention_days"` // 0 keeps deleted books forever
+	PurgeInterval time.Duration `json:"purge_interval"`
+}
+
+// URLBatchConfig limits batch URL processing
+type URLBatchConfig struct {
+	MaxURLs        int   `json:"max_urls"`         // URLs accepted per request
+	MaxResolveURLs int   `json:"max_resolve_urls"` // resolve operations accepted per request
+	MaxBodyBytes   int64 `json:"max_body_bytes"`   // size of a request body
+	Workers        int   `json:"workers"`          // URLs processed concurrently per request
+}
+
+// ResolverConfig controls how the resolve operation follows redirects
+type ResolverConfig struct {
+	MaxHops   int           `json:"max_hops"` // redirects followed before giving up
+	Timeout   time.Duration `json:"timeout"`  // for the whole chain
+	UserAgent string        `json:"user_agent"`
+}
+
+// ShortLinkConfig controls short link codes and URLs
+type ShortLinkConfig struct {
+	BaseURL    string `json:"base_url"`    // prefix of short URLs; the request host when empty
+	CodeLength int    `json:"code_length"` // length of generated codes
+}
+
+// URLLogConfig controls the privacy of URL processing and short link click logs
+type URLLogConfig struct {
+	RetentionDays     int           `json:"retention_days"`     // 0 keeps logs forever
+	RetentionMode     string        `json:"retention_mode"`     // delete, aggregate
+	RetentionInterval time.Duration `json:"retention_interval"` // how often expired logs are removed
+	IPMode            string        `json:"ip_mode"`            // full, truncate, hash
+	IPHashKey         string        `json:"-"`                  // HMAC key for the hash IP mode
+}
+
+// URLPolicyConfig restricts the URLs accepted for processing. Domain patterns
+// are exact hosts or *.example.com wildcards matching subdomains.
+type URLPolicyConfig struct {
+	AllowedSchemes    []string `json:"allowed_schemes"`
+	AllowedDomains    []string `json:"allowed_domains"` // any domain when empty
+	BlockedDomains    []string `json:"blocked_domains"`
+	AllowPrivateHosts bool     `json:"allow_private_hosts"` // private, loopback and link-local addresses and internal host names
+	MaxLength         int      `json:"max_length"`          // 0 for no limit
+}
+
+// TracingConfig controls OpenTelemetry trace export. Incoming W3C traceparent
+// headers are honoured with every exporter, including none.
+type TracingConfig struct {
+	Exporter    string  `json:"exporter"`     // none, stdout, file, otlp
+	Endpoint    string  `json:"endpoint"`     // OTLP/HTTP collector host:port
+	Insecure    bool    `json:"insecure"`     // plain HTTP to the collector
+	FilePath    string  `json:"file_path"`    // spans are appended here by the file exporter
+	ServiceName string  `json:"service_name"` // the app name when empty
+	SampleRatio float64 `json:"sample_ratio"` // fraction of new traces recorded
+}
+
+// envErrors collects the variables the last Load could not parse; they fall
+// back to their defaults
+var envErrors []error
+
+// Load reads the configuration from the environment and the .env file
 func Load() *Config {
 	// Load .env file if exists
 	godotenv.Load()
 
-	return &Config{
+	envErrors = nil
+
+	cfg := &Config{
 		Database: DatabaseConfig{
+			Driver:   getEnv("DB_DRIVER", "postgres"),
+			Path:     getEnv("DB_PATH", "library.db"),
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
 			User:     getEnv("DB_USER", "postgres"),
@@ -47,10 +156,21 @@ func Load() *Config {
 			DBName:   getEnv("DB_NAME", "library"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 			TimeZone: getEnv("DB_TIMEZONE", "UTC"),
+
+			MigrateOnStart: getEnvBool("DB_MIGRATE_ON_START", true),
+			SeedOnStart:    getEnvBool("DB_SEED_ON_START", false),
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Mode: getEnv("GIN_MODE", "debug"),
+			Port:                getEnv("SERVER_PORT", "8080"),
+			Mode:                getEnv("GIN_MODE", "debug"),
+			RequireIfMatch:      getEnvBool("REQUIRE_IF_MATCH", false),
+			ReadHeaderTimeout:   getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 10*time.Second),
+			ReadTimeout:         getEnvDuration("SERVER_READ_TIMEOUT", 60*time.Second),
+			WriteTimeout:        getEnvDuration("SERVER_WRITE_TIMEOUT", 5*time.Minute),
+			IdleTimeout:         getEnvDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+			DrainPeriod:         getEnvDuration("SERVER_DRAIN_PERIOD", 5*time.Second),
+			ShutdownTimeout:     getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			JobsShutdownTimeout: getEnvDuration("SERVER_JOBS_SHUTDOWN_TIMEOUT", 10*time.Second),
 		},
 		App: AppConfig{
 			Name:        getEnv("APP_NAME", "Library Backend"),
@@ -58,7 +178,55 @@ func Load() *Config {
 			Environment: getEnv("APP_ENV", "development"),
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
 		},
+		Admin: AdminConfig{
+			Username: getEnv("ADMIN_USERNAME", "admin"),
+			Password: getEnv("ADMIN_PASSWORD", ""),
+		},
+		Trash: TrashConfig{
+			RetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+			PurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		URLBatch: URLBatchConfig{
+			MaxURLs:        getEnvInt("URL_BATCH_MAX_URLS", 10000),
+			MaxResolveURLs: getEnvInt("URL_BATCH_MAX_RESOLVE_URLS", 100),
+			MaxBodyBytes:   int64(getEnvInt("URL_BATCH_MAX_BODY_BYTES", 16<<20)),
+			Workers:        getEnvInt("URL_BATCH_WORKERS", 8),
+		},
+		Resolver: ResolverConfig{
+			MaxHops:   getEnvInt("URL_RESOLVE_MAX_HOPS", 10),
+			Timeout:   getEnvDuration("URL_RESOLVE_TIMEOUT", 10*time.Second),
+			UserAgent: getEnv("URL_RESOLVE_USER_AGENT", "LibraryBackend-URLResolver/1.0"),
+		},
+		ShortLinks: ShortLinkConfig{
+			BaseURL:    getEnv("SHORT_LINK_BASE_URL", ""),
+			CodeLength: getEnvInt("SHORT_LINK_CODE_LENGTH", 7),
+		},
+		URLLogs: URLLogConfig{
+			RetentionDays:     getEnvInt("URL_LOG_RETENTION_DAYS", 90),
+			RetentionMode:     getEnv("URL_LOG_RETENTION_MODE", "delete"),
+			RetentionInterval: getEnvDuration("URL_LOG_RETENTION_INTERVAL", time.Hour),
+			IPMode:            getEnv("URL_LOG_IP_MODE", "truncate"),
+			IPHashKey:         getEnv("URL_LOG_IP_HASH_KEY", ""),
+		},
+		URLPolicy: URLPolicyConfig{
+			AllowedSchemes:    getEnvList("URL_ALLOWED_SCHEMES", []string{"http", "https"}),
+			AllowedDomains:    getEnvList("URL_ALLOWED_DOMAINS", nil),
+			BlockedDomains:    getEnvList("URL_BLOCKED_DOMAINS", nil),
+			AllowPrivateHosts: getEnvBool("URL_ALLOW_PRIVATE_HOSTS", false),
+			MaxLength:         getEnvInt("URL_MAX_LENGTH", 2048),
+		},
+		Tracing: TracingConfig{
+			Exporter:    getEnv("OTEL_TRACES_EXPORTER", "none"),
+			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+			Insecure:    getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+			FilePath:    getEnv("OTEL_TRACES_FILE", "traces.jsonl"),
+			ServiceName: getEnv("OTEL_SERVICE_NAME", ""),
+			SampleRatio: getEnvFloat("OTEL_TRACES_SAMPLER_RATIO", 1),
+		},
 	}
+	cfg.envErrors = envErrors
+
+	return cfg
 }
 
 func getEnv(key, defaultValue string) string {
@@ -67,3 +235,63 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		envErrors = append(envErrors, invalidEnv(key, value, "integer"))
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		envErrors = append(envErrors, invalidEnv(key, value, "boolean"))
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+		envErrors = append(envErrors, invalidEnv(key, value, "number"))
+	}
+	return defaultValue
+}
+
+// getEnvList splits a comma-separated variable, ignoring empty entries
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		envErrors = append(envErrors, invalidEnv(key, value, "duration"))
+	}
+	return defaultValue
+}
+
+func invalidEnv(key, value, kind string) error {
+	return fmt.Errorf("%s=%q is not a valid %s, using the default", key, value, kind)
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Validate reports the variables that could not be parsed when the
+// configuration was loaded and every setting the server cannot run with as
+// configured. Problems are joined into one error, one per line.
+func (c *Config) Validate() error {
+	problems := append([]error(nil), c.envErrors...)
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+	oneOf := func(key, value string, allowed ...string) {
+		check(slices.Contains(allowed, value), "%s=%q must be one of %v", key, value, allowed)
+	}
+	notNegative := func(key string, value time.Duration) {
+		check(value >= 0, "%s must not be negative", key)
+	}
+
+	oneOf("DB_DRIVER", c.Database.Driver, "postgres", "sqlite")
+	if c.Database.Driver == "sqlite" {
+		check(c.Database.Path != "", "DB_PATH must be set for sqlite")
+	} else {
+		check(c.Database.Host != "", "DB_HOST must be set")
+		check(c.Database.DBName != "", "DB_NAME must be set")
+		check(c.Database.User != "", "DB_USER must be set")
+		check(validPort(c.Database.Port), "DB_PORT=%q is not a valid port", c.Database.Port)
+	}
+
+	check(validPort(c.Server.Port), "SERVER_PORT=%q is not a valid port", c.Server.Port)
+	oneOf("GIN_MODE", c.Server.Mode, "debug", "release", "test")
+	notNegative("SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout)
+	notNegative("SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	notNegative("SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	notNegative("SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	notNegative("SERVER_DRAIN_PERIOD", c.Server.DrainPeriod)
+	check(c.Server.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+	check(c.Server.JobsShutdownTimeout > 0, "SERVER_JOBS_SHUTDOWN_TIMEOUT must be positive")
+
+	oneOf("LOG_LEVEL", c.App.LogLevel, "trace", "debug", "info", "warn", "warning", "error", "fatal", "panic")
+
+	check(c.Trash.RetentionDays >= 0, "TRASH_RETENTION_DAYS must not be negative")
+	check(c.Trash.PurgeInterval > 0, "TRASH_PURGE_INTERVAL must be positive")
+
+	check(c.URLBatch.MaxURLs > 0, "URL_BATCH_MAX_URLS must be positive")
+	check(c.URLBatch.MaxResolveURLs >= 0, "URL_BATCH_MAX_RESOLVE_URLS must not be negative")
+	check(c.URLBatch.MaxBodyBytes > 0, "URL_BATCH_MAX_BODY_BYTES must be positive")
+	check(c.URLBatch.Workers > 0, "URL_BATCH_WORKERS must be positive")
+
+	check(c.Resolver.MaxHops >= 0, "URL_RESOLVE_MAX_HOPS must not be negative")
+	check(c.Resolver.Timeout > 0, "URL_RESOLVE_TIMEOUT must be positive")
+
+	check(c.ShortLinks.CodeLength >= 4, "SHORT_LINK_CODE_LENGTH must be at least 4")
+
+	check(c.URLLogs.RetentionDays >= 0, "URL_LOG_RETENTION_DAYS must not be negative")
+	oneOf("URL_LOG_RETENTION_MODE", c.URLLogs.RetentionMode, "delete", "aggregate")
+	check(c.URLLogs.RetentionInterval > 0, "URL_LOG_RETENTION_INTERVAL must be positive")
+	oneOf("URL_LOG_IP_MODE", c.URLLogs.IPMode, "full", "truncate", "hash")
+	check(c.URLLogs.IPMode != "hash" || c.URLLogs.IPHashKey != "", "URL_LOG_IP_HASH_KEY must be set for the hash IP mode")
+
+	check(len(c.URLPolicy.AllowedSchemes) > 0, "URL_ALLOWED_SCHEMES must list at least one scheme")
+	check(c.URLPolicy.MaxLength >= 0, "URL_MAX_LENGTH must not be negative")
+
+	oneOf("OTEL_TRACES_EXPORTER", c.Tracing.Exporter, "none", "stdout", "file", "otlp")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "OTEL_TRACES_SAMPLER_RATIO must be between 0 and 1")
+
+	return errors.Join(problems...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
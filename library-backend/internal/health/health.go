@@ -0,0 +1,187 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Check statuses, from best to worst
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// Time a single check may take before it is reported down
+const checkTimeout = 5 * time.Second
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string                 `json:"status"`
+	LatencyMS float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// Check is a dependency probe registered with a Registry. A critical check
+// that is down makes the service unready; other checks only degrade it.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) Result
+}
+
+// Report is the combined outcome of the checks of a Registry
+type Report struct {
+	Status string            `json:"status"`
+	Ready  bool              `json:"ready"`
+	Reason string            `json:"reason,omitempty"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Registry holds the health checks of the subsystems and whether the service
+// is ready to receive traffic
+type Registry struct {
+	mu     sync.RWMutex
+	checks []Check
+	ready  bool
+	reason string
+}
+
+// NewRegistry returns a registry that is not ready until SetReady is called
+func NewRegistry() *Registry {
+	return &Registry{reason: "starting"}
+}
+
+// Register adds a check; checks registered under an existing name replace it
+func (r *Registry) Register(check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.checks {
+		if r.checks[i].Name == check.Name {
+			r.checks[i] = check
+			return
+		}
+	}
+	r.checks = append(r.checks, check)
+}
+
+// SetReady marks the service ready, or unready for reason, e.g. while
+// migrating or draining
+func (r *Registry) SetReady(ready bool, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ready = ready
+	r.reason = reason
+	if ready {
+		r.reason = ""
+	}
+}
+
+// Ready reports whether the service is ready and, if not, why
+func (r *Registry) Ready() (bool, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ready, r.reason
+}
+
+// Run runs the registered checks concurrently, only the critical ones if
+// criticalOnly is set. The report is down when the service is unready or a
+// critical check is down, and degraded when any other check is not up.
+func (r *Registry) Run(ctx context.Context, criticalOnly bool) *Report {
+	r.mu.RLock()
+	checks := make([]Check, 0, len(r.checks))
+	for _, check := range r.checks {
+		if check.Critical || !criticalOnly {
+			checks = append(checks, check)
+		}
+	}
+	ready, reason := r.ready, r.reason
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status: StatusUp,
+		Ready:  ready,
+		Reason: reason,
+		Checks: make(map[string]Result, len(checks)),
+	}
+	if !ready {
+		report.Status = StatusDown
+	}
+
+	// Sorted so the first failing critical check names the reason
+	order := make([]int, len(checks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return checks[order[a]].Name < checks[order[b]].Name })
+
+	for _, i := range order {
+		check, result := checks[i], results[i]
+		report.Checks[check.Name] = result
+
+		switch {
+		case result.Status == StatusUp:
+		case check.Critical && result.Status == StatusDown:
+			report.Status = StatusDown
+			if report.Ready {
+				report.Ready = false
+				report.Reason = check.Name + " is down"
+			}
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// runCheck runs check with a timeout and measures its latency
+func runCheck(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result := check.Run(ctx)
+	if result.Status == "" {
+		result.Status = StatusUp
+	}
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+
+	return result
+}
+
+// Worse returns the worse of two statuses
+func Worse(a, b string) string {
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+func rank(status string) int {
+	switch status {
+	case StatusDown:
+		return 2
+	case StatusDegraded:
+		return 1
+	default:
+		return 0
+	}
+}
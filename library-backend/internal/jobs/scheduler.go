@@ -0,0 +1,180 @@
+package jobs
+
+import (
+	"context"
+	"library-backend/internal/health"
+	"library-backend/internal/requestctx"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a unit of background work run periodically by the Scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// A job that has not finished a run for this many intervals is reported down
+const missedIntervals = 2
+
+// Scheduler runs registered jobs on their intervals until its context is cancelled
+type Scheduler struct {
+	jobs   []Job
+	logger *logrus.Logger
+	wg     sync.WaitGroup
+
+	mu         sync.Mutex
+	startedAt  time.Time
+	heartbeats map[string]*heartbeat
+}
+
+// heartbeat records the last run of a job
+type heartbeat struct {
+	lastRun     time.Time // when the last run finished
+	lastSuccess time.Time
+	lastError   string
+	failures    int // consecutive failed runs
+}
+
+func NewScheduler(logger *logrus.Logger) *Scheduler {
+	return &Scheduler{logger: logger, heartbeats: make(map[string]*heartbeat)}
+}
+
+// Register adds a job; jobs with a non-positive interval are skipped
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		s.logger.WithField("job", job.Name).Info("Job disabled")
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches every registered job in its own goroutine. Each job runs once
+// immediately and then on every tick of its interval.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.startedAt = time.Now()
+	s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait blocks until every job has returned after the context passed to Start
+// is cancelled, or until ctx is done
+func (s *Scheduler) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	startTime := time.Now()
+	entry := s.logger.WithField("job", job.Name)
+
+	// Services log through the job's logger like through a request's
+	err := job.Run(requestctx.WithLogger(ctx, entry))
+	s.beat(job, err)
+	if err != nil {
+		entry.WithError(err).Error("Job failed")
+		return
+	}
+
+	entry.WithField("duration", time.Since(startTime)).Debug("Job completed")
+}
+
+func (s *Scheduler) beat(job Job, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	beat := s.heartbeats[job.Name]
+	if beat == nil {
+		beat = &heartbeat{}
+		s.heartbeats[job.Name] = beat
+	}
+
+	beat.lastRun = time.Now()
+	if err != nil {
+		beat.lastError = err.Error()
+		beat.failures++
+		return
+	}
+	beat.lastSuccess = beat.lastRun
+	beat.lastError = ""
+	beat.failures = 0
+}
+
+// HealthCheck reports a job down when it has not finished a run for two of
+// its intervals and degraded when its last run failed
+func (s *Scheduler) HealthCheck() health.Check {
+	return health.Check{Name: "jobs", Run: s.checkJobs}
+}
+
+func (s *Scheduler) checkJobs(ctx context.Context) health.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := health.Result{Status: health.StatusUp, Details: make(map[string]interface{}, len(s.jobs))}
+
+	for _, job := range s.jobs {
+		status := health.StatusUp
+		detail := map[string]interface{}{"interval": job.Interval.String()}
+
+		// Jobs are measured from their start until they have run once
+		since := s.startedAt
+		if beat := s.heartbeats[job.Name]; beat != nil {
+			since = beat.lastRun
+			detail["last_run"] = beat.lastRun.UTC().Format(time.RFC3339)
+			if !beat.lastSuccess.IsZero() {
+				detail["last_success"] = beat.lastSuccess.UTC().Format(time.RFC3339)
+			}
+			if beat.lastError != "" {
+				status = health.StatusDegraded
+				detail["last_error"] = beat.lastError
+				detail["consecutive_failures"] = beat.failures
+			}
+		}
+
+		if since.IsZero() || now.Sub(since) > missedIntervals*job.Interval {
+			status = health.StatusDown
+		}
+
+		detail["status"] = status
+		result.Details[job.Name] = detail
+		result.Status = health.Worse(result.Status, status)
+	}
+
+	return result
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"library-backend/internal/config"
+	"library-backend/internal/requestctx"
+	"library-backend/internal/service"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TrashPurgeJob permanently removes books that have been in the trash for
+// longer than the configured retention period
+func TrashPurgeJob(bookService *service.BookService, cfg *config.TrashConfig, logger *logrus.Logger) Job {
+	interval := cfg.PurgeInterval
+	if cfg.RetentionDays <= 0 {
+		interval = 0
+	}
+
+	return Job{
+		Name:     "trash-purge",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			ctx = requestctx.WithActor(ctx, "system:trash-purge")
+			cutoff := time.Now().UTC().AddDate(0, 0, -cfg.RetentionDays)
+
+			purged, err := bookService.PurgeDeletedBefore(ctx, cutoff)
+			if err != nil {
+				return err
+			}
+
+			if purged > 0 {
+				logger.WithFields(logrus.Fields{
+					"purged": purged,
+					"cutoff": cutoff.Format(time.RFC3339),
+				}).Info("Purged expired books from trash")
+			}
+			return nil
+		},
+	}
+}
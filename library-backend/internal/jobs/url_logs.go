@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// URLLogRetentionJob removes URL processing logs older than the configured
+// retention period, aggregating them into daily counts first if configured
+func URLLogRetentionJob(urlLogService *service.URLLogService, cfg *config.URLLogConfig, logger *logrus.Logger) Job {
+	interval := cfg.RetentionInterval
+	if cfg.RetentionDays <= 0 {
+		interval = 0
+	}
+	aggregate := cfg.RetentionMode == models.URLLogRetentionAggregate
+
+	return Job{
+		Name:     "url-log-retention",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			cutoff := time.Now().UTC().AddDate(0, 0, -cfg.RetentionDays)
+
+			removed, err := urlLogService.ApplyRetention(ctx, cutoff, aggregate)
+			if err != nil {
+				return err
+			}
+
+			if removed > 0 {
+				logger.WithFields(logrus.Fields{
+					"removed":    removed,
+					"aggregated": aggregate,
+					"cutoff":     cutoff.Format(time.RFC3339),
+				}).Info("Removed expired URL processing logs")
+			}
+			return nil
+		},
+	}
+}
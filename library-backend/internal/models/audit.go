@@ -0,0 +1,103 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// Audited entity types
+const (
+	AuditEntityBook              = "book"
+	AuditEntityURLRule           = "url_rule"
+	AuditEntityTrackingParamRule = "tracking_param_rule"
+	AuditEntityUser              = "user"
+)
+
+// AuditEvent GORM Model - One row per change to an audited entity
+type AuditEvent struct {
+	ID           uint         `json:"id" gorm:"primaryKey"`
+	EntityType   string       `json:"entity_type" gorm:"type:varchar(50);not null;index:idx_audit_entity"`
+	EntityID     uint         `json:"entity_id" gorm:"not null;index:idx_audit_entity"`
+	Action       string       `json:"action" gorm:"type:varchar(20);not null;index"`
+	Actor        string       `json:"actor" gorm:"type:varchar(255);not null;index"`
+	ClaimedActor string       `json:"claimed_actor,omitempty" gorm:"type:varchar(255)"` // unverified X-Actor header of an anonymous request
+	RequestID    string       `json:"request_id,omitempty" gorm:"type:varchar(64);index"`
+	IPAddress    string       `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	Changes      AuditChanges `json:"changes" gorm:"type:text"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"index"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// FieldChange holds the before/after value of a single field
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditChanges maps JSON field names to their change, stored as a JSON document
+type AuditChanges map[string]FieldChange
+
+// Value implements driver.Valuer
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *AuditChanges) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = AuditChanges{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported audit changes type: %T", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// AuditFilter for querying the audit trail
+type AuditFilter struct {
+	EntityType string     `form:"entity_type" json:"entity_type,omitempty"`
+	EntityID   *uint      `form:"entity_id" json:"entity_id,omitempty"`
+	Action     string     `form:"action" json:"action,omitempty"`
+	Actor      string     `form:"actor" json:"actor,omitempty"`
+	RequestID  string     `form:"request_id" json:"request_id,omitempty"`
+	From       *time.Time `form:"from" json:"from,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" json:"to,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int        `form:"limit" json:"limit,omitempty"`
+	Offset     int        `form:"offset" json:"offset,omitempty"`
+}
+
+type AuditEventsResponse struct {
+	Success bool         `json:"success"`
+	Data    []AuditEvent `json:"data"`
+	Total   int64        `json:"total"`
+	Limit   int          `json:"limit,omitempty"`
+	Offset  int          `json:"offset,omitempty"`
+	Message string       `json:"message,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -12,8 +13,9 @@ type Book struct {
 	Title       string         `json:"title" gorm:"type:varchar(255);not null;index" validate:"required,min=1,max=255"`
 	Author      string         `json:"author" gorm:"type:varchar(255);not null;index" validate:"required,min=1,max=255"`
 	Year        int            `json:"year" gorm:"not null;index;check:year >= 1000 AND year <= 2024" validate:"required,min=1000,max=2024"`
-	ISBN        string         `json:"isbn,omitempty" gorm:"type:varchar(13);uniqueIndex" validate:"omitempty,len=13"`
+	ISBN        string         `json:"isbn,omitempty" gorm:"type:varchar(13);uniqueIndex:idx_books_isbn_active,where:deleted_at IS NULL AND isbn <> ''" validate:"omitempty,len=13"`
 	Description string         `json:"description,omitempty" gorm:"type:text"`
+	Version     uint           `json:"version" gorm:"not null;default:1"` // Incremented on every change for optimistic locking
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete
@@ -24,6 +26,11 @@ func (Book) TableName() string {
 	return "books"
 }
 
+// ETag returns the entity tag identifying the current version of the book
+func (b *Book) ETag() string {
+	return fmt.Sprintf(`"%d"`, b.Version)
+}
+
 // DTOs (Data Transfer Objects)
 type CreateBookRequest struct {
 	Title       string `json:"title" validate:"required,min=1,max=255"`
@@ -50,6 +57,12 @@ type BookFilter struct {
 	Offset int    `form:"offset" json:"offset,omitempty"`
 }
 
+// TrashedBook is a soft-deleted book as listed in the trash
+type TrashedBook struct {
+	Book
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
 // API Response structures
 type BookResponse struct {
 	Success bool   `json:"success"`
@@ -66,6 +79,15 @@ type BooksResponse struct {
 	Message string `json:"message,omitempty"`
 }
 
+type TrashResponse struct {
+	Success bool          `json:"success"`
+	Data    []TrashedBook `json:"data"`
+	Total   int64         `json:"total"`
+	Limit   int           `json:"limit,omitempty"`
+	Offset  int           `json:"offset,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
 // Convert DTO to Model
 func (req *CreateBookRequest) ToModel() *Book {
 	return &Book{
@@ -74,6 +96,7 @@ func (req *CreateBookRequest) ToModel() *Book {
 		Year:        req.Year,
 		ISBN:        req.ISBN,
 		Description: req.Description,
+		Version:     1,
 	}
 }
 
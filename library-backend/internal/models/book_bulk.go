@@ -0,0 +1,55 @@
+package models
+
+import "encoding/json"
+
+// Bulk operation kinds
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+)
+
+// Bulk execution modes
+const (
+	BulkModeAtomic     = "atomic"      // all operations succeed or none are applied
+	BulkModeBestEffort = "best_effort" // each operation is applied independently
+)
+
+// BulkBookRequest is a batch of book operations
+type BulkBookRequest struct {
+	Mode       string              `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BulkBookOperation `json:"operations" validate:"required,min=1,max=1000,dive"`
+}
+
+// BulkBookOperation is a single create, update or delete. Data holds a
+// CreateBookRequest for creates and an UpdateBookRequest for updates; Version
+// makes updates and deletes conditional like If-Match.
+type BulkBookOperation struct {
+	Op      string          `json:"op" validate:"required,oneof=create update delete"`
+	ID      uint            `json:"id,omitempty" validate:"required_unless=Op create"`
+	Version uint            `json:"version,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
+
+// BulkBookResult reports the outcome of one operation, in request order
+type BulkBookResult struct {
+	Index   int               `json:"index"`
+	Op      string            `json:"op"`
+	ID      uint              `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Success bool              `json:"success"`
+	Data    *Book             `json:"data,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Details string            `json:"details,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+type BulkBookResponse struct {
+	Success   bool             `json:"success"`
+	Mode      string           `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkBookResult `json:"results"`
+	Message   string           `json:"message,omitempty"`
+}
@@ -1,7 +1,5 @@
 package models
 
-import "net/http"
-
 // Standard API Error Response
 type ErrorResponse struct {
 	Success   bool   `json:"success"`
@@ -9,14 +7,16 @@ type ErrorResponse struct {
 	Code      string `json:"code,omitempty"`
 	Details   string `json:"details,omitempty"`
 	Timestamp string `json:"timestamp,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // Validation Error Response
 type ValidationErrorResponse struct {
-	Success bool                    `json:"success"`
-	Error   string                  `json:"error"`
-	Fields  map[string]string       `json:"fields,omitempty"`
-	Details []ValidationErrorDetail `json:"details,omitempty"`
+	Success   bool                    `json:"success"`
+	Error     string                  `json:"error"`
+	Fields    map[string]string       `json:"fields,omitempty"`
+	Details   []ValidationErrorDetail `json:"details,omitempty"`
+	RequestID string                  `json:"request_id,omitempty"`
 }
 
 type ValidationErrorDetail struct {
@@ -41,27 +41,3 @@ type PaginationMeta struct {
 	HasNext    bool  `json:"has_next"`
 	HasPrev    bool  `json:"has_prev"`
 }
-
-// Common HTTP Errors
-var (
-	ErrBookNotFound     = &ErrorResponse{Success: false, Error: "Book not found", Code: "BOOK_NOT_FOUND"}
-	ErrInvalidBookID    = &ErrorResponse{Success: false, Error: "Invalid book ID", Code: "INVALID_BOOK_ID"}
-	ErrInvalidRequest   = &ErrorResponse{Success: false, Error: "Invalid request format", Code: "INVALID_REQUEST"}
-	ErrValidationFailed = &ErrorResponse{Success: false, Error: "Validation failed", Code: "VALIDATION_FAILED"}
-	ErrInternalServer   = &ErrorResponse{Success: false, Error: "Internal server error", Code: "INTERNAL_ERROR"}
-	ErrDatabaseError    = &ErrorResponse{Success: false, Error: "Database error", Code: "DATABASE_ERROR"}
-)
-
-// HTTP Status Code Mapping
-func (e *ErrorResponse) StatusCode() int {
-	switch e.Code {
-	case "BOOK_NOT_FOUND":
-		return http.StatusNotFound
-	case "INVALID_BOOK_ID", "INVALID_REQUEST", "VALIDATION_FAILED":
-		return http.StatusBadRequest
-	case "DATABASE_ERROR", "INTERNAL_ERROR":
-		return http.StatusInternalServerError
-	default:
-		return http.StatusInternalServerError
-	}
-}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// ShortLink GORM Model - A short code redirecting to a target URL
+type ShortLink struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Code         string     `json:"code" gorm:"type:varchar(64);not null;uniqueIndex"`
+	TargetURL    string     `json:"target_url" gorm:"type:text;not null"`
+	RedirectType int        `json:"redirect_type" gorm:"not null"` // 301 or 302
+	Custom       bool       `json:"custom" gorm:"not null"`        // code chosen by the creator
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" gorm:"index"`
+	Clicks       int64      `json:"clicks" gorm:"not null"`
+	CreatedBy    string     `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (ShortLink) TableName() string {
+	return "short_links"
+}
+
+// Expired reports whether the link stopped redirecting at now
+func (l *ShortLink) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// ShortLinkClick GORM Model - One followed short link
+type ShortLinkClick struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ShortLinkID uint      `json:"short_link_id" gorm:"not null;index"`
+	Referrer    string    `json:"referrer,omitempty" gorm:"type:text"`
+	UserAgent   string    `json:"user_agent,omitempty" gorm:"type:text"`
+	IPAddress   string    `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+func (ShortLinkClick) TableName() string {
+	return "short_link_clicks"
+}
+
+// CreateShortLinkRequest creates a short link. Operation optionally runs the
+// target through URL processing before it is stored.
+type CreateShortLinkRequest struct {
+	URL           string     `json:"url" validate:"required,url"`
+	Alias         string     `json:"alias,omitempty" validate:"omitempty,min=3,max=64"`
+	RedirectType  int        `json:"redirect_type,omitempty" validate:"omitempty,oneof=301 302"` // default 302
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	Operation     string     `json:"operation,omitempty" validate:"omitempty,oneof=redirection canonical normalize resolve all"`
+	CanonicalMode string     `json:"canonical_mode,omitempty" validate:"omitempty,oneof=strip_all strip_tracking"`
+}
+
+// ToModel converts the request into a link without its code
+func (req *CreateShortLinkRequest) ToModel() *ShortLink {
+	link := &ShortLink{
+		TargetURL:    req.URL,
+		RedirectType: req.RedirectType,
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if link.RedirectType == 0 {
+		link.RedirectType = http.StatusFound
+	}
+	return link
+}
+
+type ShortLinkResponse struct {
+	Success  bool       `json:"success"`
+	Data     *ShortLink `json:"data,omitempty"`
+	ShortURL string     `json:"short_url,omitempty"`
+	Message  string     `json:"message,omitempty"`
+}
+
+// ClickCount is the number of clicks sharing a value
+type ClickCount struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+// ShortLinkStats breaks down the clicks of a short link
+type ShortLinkStats struct {
+	Link        *ShortLink   `json:"link"`
+	Clicks      int64        `json:"clicks"`
+	LastClickAt *time.Time   `json:"last_click_at,omitempty"`
+	Referrers   []ClickCount `json:"referrers"`
+	UserAgents  []ClickCount `json:"user_agents"`
+}
+
+type ShortLinkStatsResponse struct {
+	Success bool            `json:"success"`
+	Data    *ShortLinkStats `json:"data"`
+	Message string          `json:"message,omitempty"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Canonicalization modes of the canonical operation
+const (
+	CanonicalStripAll      = "strip_all"      // drop the whole query string
+	CanonicalStripTracking = "strip_tracking" // drop only tracking parameters
+)
+
+// Tracking parameter rule actions
+const (
+	TrackingParamDeny  = "deny"  // always strip the parameter
+	TrackingParamAllow = "allow" // never strip the parameter, even if it is a known tracker
+)
+
+// BuiltinTrackingParams are stripped in strip_tracking mode unless a domain
+// allows them. Entries ending in * match any parameter with that prefix.
+var BuiltinTrackingParams = []string{
+	"utm_*",
+	"fbclid", "gclid", "gclsrc", "dclid", "gbraid", "wbraid", "msclkid", "yclid",
+	"twclid", "ttclid", "li_fat_id", "igshid",
+	"mc_cid", "mc_eid",
+	"_ga", "_gl", "_hsenc", "_hsmi", "__hssc", "__hstc", "__hsfp", "hsCtaTracking",
+	"mkt_tok", "oly_anon_id", "oly_enc_id", "vero_id", "vero_conv",
+	"s_cid", "ref_src", "spm",
+}
+
+// TrackingParamRule GORM Model - A custom allow or deny entry for query
+// parameters, either global (empty domain) or scoped to a domain
+type TrackingParamRule struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Domain    string    `json:"domain,omitempty" gorm:"type:varchar(255);not null;uniqueIndex:idx_tracking_param_rule"` // exact host or *.example.com
+	Param     string    `json:"param" gorm:"type:varchar(100);not null;uniqueIndex:idx_tracking_param_rule"`            // trailing * matches a prefix
+	Action    string    `json:"action" gorm:"type:varchar(10);not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (TrackingParamRule) TableName() string {
+	return "tracking_param_rules"
+}
+
+type TrackingParamRuleRequest struct {
+	Domain string `json:"domain,omitempty" validate:"omitempty,max=255"`
+	Param  string `json:"param" validate:"required,min=1,max=100"`
+	Action string `json:"action" validate:"required,oneof=allow deny"`
+}
+
+type TrackingParamRulesResponse struct {
+	Success bool                `json:"success"`
+	Data    []TrackingParamRule `json:"data"`
+	Builtin []string            `json:"builtin"`
+	Total   int64               `json:"total"`
+	Message string              `json:"message,omitempty"`
+}
+
+type TrackingParamRuleResponse struct {
+	Success bool               `json:"success"`
+	Data    *TrackingParamRule `json:"data,omitempty"`
+	Message string             `json:"message,omitempty"`
+}
+
+// ToModel converts the request into a rule
+func (req *TrackingParamRuleRequest) ToModel() *TrackingParamRule {
+	return &TrackingParamRule{
+		Domain: req.Domain,
+		Param:  req.Param,
+		Action: req.Action,
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Retention modes of URL processing logs
+const (
+	URLLogRetentionDelete    = "delete"    // drop expired logs
+	URLLogRetentionAggregate = "aggregate" // fold expired logs into daily counts, then drop them
+)
+
+// URLLogAggregate GORM Model - Daily counts of URL processing logs that were
+// removed by the retention job
+type URLLogAggregate struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Day       time.Time `json:"day" gorm:"not null;uniqueIndex:idx_url_log_aggregate"`
+	Operation string    `json:"operation" gorm:"type:varchar(20);not null;uniqueIndex:idx_url_log_aggregate"`
+	Requests  int64     `json:"requests" gorm:"not null"`
+	Changed   int64     `json:"changed" gorm:"not null"` // processed URL differed from the original
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (URLLogAggregate) TableName() string {
+	return "url_log_aggregates"
+}
+
+// URLLogErasure reports the rows removed for an erasure request
+type URLLogErasure struct {
+	ProcessLogs     int64 `json:"process_logs"`
+	RedirectHops    int64 `json:"redirect_hops"`
+	ShortLinkClicks int64 `json:"short_link_clicks"`
+}
+
+// Sort orders of URL processing log listings; a leading - sorts descending
+const (
+	URLLogSortCreatedAt     = "created_at"
+	URLLogSortCreatedAtDesc = "-created_at"
+	URLLogSortID            = "id"
+	URLLogSortIDDesc        = "-id"
+)
+
+// URLLogFilter selects URL processing logs for listing and export
+type URLLogFilter struct {
+	Operation string     `form:"operation" json:"operation,omitempty"`
+	From      *time.Time `form:"from" json:"from,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        *time.Time `form:"to" json:"to,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	Domain    string     `form:"domain" json:"domain,omitempty"` // host of the original URL
+	IP        string     `form:"ip" json:"ip,omitempty"`
+	Sort      string     `form:"sort" json:"sort,omitempty" validate:"omitempty,oneof=created_at -created_at id -id"`
+	Cursor    string     `form:"cursor" json:"cursor,omitempty"`
+	Limit     int        `form:"limit" json:"limit,omitempty"`
+}
+
+type URLLogsResponse struct {
+	Success    bool            `json:"success"`
+	Data       []URLProcessLog `json:"data"`
+	NextCursor string          `json:"next_cursor,omitempty"` // absent on the last page
+	Limit      int             `json:"limit"`
+	Message    string          `json:"message,omitempty"`
+}
+
+type URLLogResponse struct {
+	Success bool           `json:"success"`
+	Data    *URLProcessLog `json:"data,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
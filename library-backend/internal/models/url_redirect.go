@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// URLRedirectHop GORM Model - One request made while resolving a URL with the
+// resolve operation, stored with the URLProcessLog it belongs to
+type URLRedirectHop struct {
+	ID         uint      `json:"-" gorm:"primaryKey"`
+	LogID      uint      `json:"-" gorm:"not null;index"`
+	Position   int       `json:"position" gorm:"not null"`
+	URL        string    `json:"url" gorm:"type:text;not null"`
+	Method     string    `json:"method" gorm:"type:varchar(10);not null"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Location   string    `json:"location,omitempty" gorm:"type:text"` // absolute redirect target
+	Downgrade  bool      `json:"downgrade,omitempty" gorm:"not null"` // redirects from https to http
+	Error      string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time `json:"-"`
+}
+
+func (URLRedirectHop) TableName() string {
+	return "url_redirect_hops"
+}
+
+// RedirectChain is the outcome of following the redirects of a URL
+type RedirectChain struct {
+	Hops            []URLRedirectHop `json:"hops"`
+	Loop            bool             `json:"loop"`              // a redirect pointed back to a visited URL
+	Downgrade       bool             `json:"downgrade"`         // some redirect went from https to http
+	MaxHopsExceeded bool             `json:"max_hops_exceeded"` // stopped before reaching a final response
+}
@@ -8,15 +8,16 @@ import (
 
 // URLProcessLog - Optional: Log URL processing for analytics
 type URLProcessLog struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	OriginalURL  string         `json:"original_url" gorm:"type:text;not null"`
-	ProcessedURL string         `json:"processed_url" gorm:"type:text;not null"`
-	Operation    string         `json:"operation" gorm:"type:varchar(20);not null"`
-	IPAddress    string         `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
-	UserAgent    string         `json:"user_agent,omitempty" gorm:"type:text"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	ID           uint             `json:"id" gorm:"primaryKey"`
+	OriginalURL  string           `json:"original_url" gorm:"type:text;not null"`
+	ProcessedURL string           `json:"processed_url" gorm:"type:text;not null"`
+	Operation    string           `json:"operation" gorm:"type:varchar(20);not null"`
+	IPAddress    string           `json:"ip_address,omitempty" gorm:"type:varchar(45)"`
+	UserAgent    string           `json:"user_agent,omitempty" gorm:"type:text"`
+	Hops         []URLRedirectHop `json:"hops,omitempty" gorm:"foreignKey:LogID"` // resolve operation only
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt   `json:"-" gorm:"index"`
 }
 
 func (URLProcessLog) TableName() string {
@@ -25,14 +26,39 @@ func (URLProcessLog) TableName() string {
 
 // Request/Response DTOs
 type URLRequest struct {
-	URL       string `json:"url" validate:"required,url"`
-	Operation string `json:"operation" validate:"required,oneof=redirection canonical all"`
+	URL           string `json:"url" validate:"required,url"`
+	Operation     string `json:"operation" validate:"required,oneof=redirection canonical normalize resolve all"`
+	CanonicalMode string `json:"canonical_mode,omitempty" validate:"omitempty,oneof=strip_all strip_tracking"` // default strip_all
 }
 
 type URLResponse struct {
-	Success      bool   `json:"success"`
-	ProcessedURL string `json:"processed_url"`
-	Original     string `json:"original_url"`
-	Operation    string `json:"operation"`
-	LogID        uint   `json:"log_id,omitempty"`
+	Success       bool           `json:"success"`
+	ProcessedURL  string         `json:"processed_url"`
+	Original      string         `json:"original_url"`
+	Operation     string         `json:"operation"`
+	LogID         uint           `json:"log_id,omitempty"`
+	AppliedRules  []string       `json:"applied_rules,omitempty"`
+	RemovedParams []string       `json:"removed_params,omitempty"`
+	Redirects     *RedirectChain `json:"redirects,omitempty"`
+}
+
+// URLBatchResult reports the outcome of one URL of a batch, in input order
+type URLBatchResult struct {
+	Index   int               `json:"index"`
+	URL     string            `json:"url"`
+	Success bool              `json:"success"`
+	Data    *URLResponse      `json:"data,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Details string            `json:"details,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+type URLBatchResponse struct {
+	Success   bool             `json:"success"`
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []URLBatchResult `json:"results"`
+	Message   string           `json:"message,omitempty"`
 }
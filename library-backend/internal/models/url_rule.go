@@ -0,0 +1,172 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Lowercasing modes of a URL rule
+const (
+	LowercaseNone = "none"
+	LowercaseHost = "host"
+	LowercasePath = "path" // host and path
+	LowercaseAll  = "all"  // host, path, query and fragment
+)
+
+// URLRule GORM Model - A rewrite rule applied by the redirection operation.
+// Enabled rules are evaluated in ascending priority; every rule whose match
+// conditions hold applies its actions, until a matching rule marked Final.
+type URLRule struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description string `json:"description,omitempty" gorm:"type:text"`
+	Priority    int    `json:"priority" gorm:"not null;index"`
+	Enabled     bool   `json:"enabled" gorm:"not null"`
+	Final       bool   `json:"final" gorm:"not null"`
+
+	// Match conditions - empty conditions match every URL
+	MatchHost       string `json:"match_host,omitempty" gorm:"type:varchar(255)"` // exact host or *.example.com
+	MatchPathPrefix string `json:"match_path_prefix,omitempty" gorm:"type:text"`
+	MatchPathRegex  string `json:"match_path_regex,omitempty" gorm:"type:text"`
+
+	// Actions
+	SetScheme          string     `json:"set_scheme,omitempty" gorm:"type:varchar(10)"`
+	SetHost            string     `json:"set_host,omitempty" gorm:"type:varchar(255)"`
+	PathRewritePattern string     `json:"path_rewrite_pattern,omitempty" gorm:"type:text"`
+	PathRewriteReplace string     `json:"path_rewrite_replace,omitempty" gorm:"type:text"`
+	Lowercase          string     `json:"lowercase" gorm:"type:varchar(10);not null"`
+	AddQueryParams     StringMap  `json:"add_query_params,omitempty" gorm:"type:text"`
+	RemoveQueryParams  StringList `json:"remove_query_params,omitempty" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (URLRule) TableName() string {
+	return "url_rules"
+}
+
+// DefaultURLRules returns the rules installed with a fresh database. They
+// reproduce the original hardcoded redirection behavior.
+func DefaultURLRules() []URLRule {
+	return []URLRule{
+		{
+			Name:        "byfood-canonical-host",
+			Description: "Redirect every URL to www.byfood.com and lowercase it",
+			Priority:    100,
+			Enabled:     true,
+			SetHost:     "www.byfood.com",
+			Lowercase:   LowercaseAll,
+		},
+	}
+}
+
+// URLRuleRequest creates or replaces a URL rule
+type URLRuleRequest struct {
+	Name               string            `json:"name" validate:"required,min=1,max=100"`
+	Description        string            `json:"description,omitempty"`
+	Priority           int               `json:"priority"`
+	Enabled            *bool             `json:"enabled,omitempty"`
+	Final              bool              `json:"final"`
+	MatchHost          string            `json:"match_host,omitempty" validate:"omitempty,max=255"`
+	MatchPathPrefix    string            `json:"match_path_prefix,omitempty"`
+	MatchPathRegex     string            `json:"match_path_regex,omitempty"`
+	SetScheme          string            `json:"set_scheme,omitempty" validate:"omitempty,oneof=http https"`
+	SetHost            string            `json:"set_host,omitempty" validate:"omitempty,max=255"`
+	PathRewritePattern string            `json:"path_rewrite_pattern,omitempty" validate:"required_with=PathRewriteReplace"`
+	PathRewriteReplace string            `json:"path_rewrite_replace,omitempty"`
+	Lowercase          string            `json:"lowercase,omitempty" validate:"omitempty,oneof=none host path all"`
+	AddQueryParams     map[string]string `json:"add_query_params,omitempty"`
+	RemoveQueryParams  []string          `json:"remove_query_params,omitempty"`
+}
+
+type URLRuleResponse struct {
+	Success bool     `json:"success"`
+	Data    *URLRule `json:"data,omitempty"`
+	Message string   `json:"message,omitempty"`
+}
+
+type URLRulesResponse struct {
+	Success bool      `json:"success"`
+	Data    []URLRule `json:"data"`
+	Total   int64     `json:"total"`
+	Message string    `json:"message,omitempty"`
+}
+
+// ToModel converts the request into a rule
+func (req *URLRuleRequest) ToModel() *URLRule {
+	rule := &URLRule{}
+	req.ApplyToModel(rule)
+	return rule
+}
+
+// ApplyToModel replaces every editable field of rule
+func (req *URLRuleRequest) ApplyToModel(rule *URLRule) {
+	rule.Name = req.Name
+	rule.Description = req.Description
+	rule.Priority = req.Priority
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	rule.Final = req.Final
+	rule.MatchHost = req.MatchHost
+	rule.MatchPathPrefix = req.MatchPathPrefix
+	rule.MatchPathRegex = req.MatchPathRegex
+	rule.SetScheme = req.SetScheme
+	rule.SetHost = req.SetHost
+	rule.PathRewritePattern = req.PathRewritePattern
+	rule.PathRewriteReplace = req.PathRewriteReplace
+	rule.Lowercase = req.Lowercase
+	if rule.Lowercase == "" {
+		rule.Lowercase = LowercaseNone
+	}
+	rule.AddQueryParams = req.AddQueryParams
+	rule.RemoveQueryParams = req.RemoveQueryParams
+}
+
+// StringMap is a string map stored as a JSON document
+type StringMap map[string]string
+
+// Value implements driver.Valuer
+func (m StringMap) Value() (driver.Value, error) {
+	return jsonValue(m)
+}
+
+// Scan implements sql.Scanner
+func (m *StringMap) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+// StringList is a string slice stored as a JSON document
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	return jsonValue(l)
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	return scanJSON(value, l)
+}
+
+func jsonValue(v interface{}) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("unsupported JSON column type: %T", value)
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Bucket sizes of the URL processing time series
+const (
+	StatsIntervalHour = "hour"
+	StatsIntervalDay  = "day"
+	StatsIntervalWeek = "week"
+)
+
+// URLStatsFilter selects the logs covered by the URL processing statistics
+type URLStatsFilter struct {
+	From     *time.Time `form:"from" json:"from,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       *time.Time `form:"to" json:"to,omitempty" time_format:"2006-01-02T15:04:05Z07:00"`
+	Interval string     `form:"interval" json:"interval" validate:"omitempty,oneof=hour day week"`
+	Top      int        `form:"top" json:"top,omitempty"` // entries per top-N list
+}
+
+// URLStatsBucket counts the logs of one interval, starting at Start
+type URLStatsBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
+}
+
+// URLStatsCount counts the logs sharing a value
+type URLStatsCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// URLStats summarizes URL processing over a time range
+type URLStats struct {
+	From             *time.Time       `json:"from,omitempty"`
+	To               *time.Time       `json:"to,omitempty"`
+	Interval         string           `json:"interval"`
+	TotalRequests    int64            `json:"total_requests"`
+	ByOperation      map[string]int64 `json:"by_operation"`
+	UniqueClientIPs  int64            `json:"unique_client_ips"`
+	ChangedRequests  int64            `json:"changed_requests"` // processed URL differs from the original
+	ChangedRatio     float64          `json:"changed_ratio"`
+	Series           []URLStatsBucket `json:"series"`
+	TopDomains       []URLStatsCount  `json:"top_domains"`
+	TopProcessedURLs []URLStatsCount  `json:"top_processed_urls"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// User roles
+const (
+	RoleAdmin = "admin" // may use the administrative endpoints
+)
+
+// Roles lists every valid user role
+var Roles = []string{RoleAdmin}
+
+// User GORM Model - An account authenticating with HTTP Basic credentials,
+// next to the admin account configured through the environment
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"type:varchar(255);not null;uniqueIndex"`
+	PasswordHash string    `json:"-" gorm:"type:varchar(255);not null"` // bcrypt
+	Role         string    `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/pkg/database"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormBookRepository stores books in the database
+type GormBookRepository struct {
+	db   *database.Database // for the dialect helpers
+	conn *gorm.DB           // the database or the current transaction
+}
+
+func NewGormBookRepository(db *database.Database) *GormBookRepository {
+	return &GormBookRepository{db: db, conn: db.DB}
+}
+
+func (r *GormBookRepository) Transaction(ctx context.Context, fn func(repo BookRepository) error) error {
+	return r.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormBookRepository{db: r.db, conn: tx})
+	})
+}
+
+// scoped starts a query on the books in scope
+func (r *GormBookRepository) scoped(ctx context.Context, scope Scope) *gorm.DB {
+	query := r.conn.WithContext(ctx).Model(&models.Book{})
+
+	switch scope {
+	case ScopeTrashed:
+		return query.Unscoped().Where("deleted_at IS NOT NULL")
+	case ScopeAll:
+		return query.Unscoped()
+	default:
+		return query
+	}
+}
+
+func (r *GormBookRepository) Get(ctx context.Context, id uint, scope Scope) (*models.Book, error) {
+	var book models.Book
+
+	if err := r.scoped(ctx, scope).First(&book, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+func (r *GormBookRepository) List(ctx context.Context, filter *models.BookFilter) ([]models.Book, int64, error) {
+	var books []models.Book
+	var total int64
+
+	query := r.scoped(ctx, ScopeActive)
+
+	// Apply filters
+	if filter.Title != "" {
+		query = query.Where(r.db.ContainsFold("title", filter.Title))
+	}
+	if filter.Author != "" {
+		query = query.Where(r.db.ContainsFold("author", filter.Author))
+	}
+	if filter.Year != nil {
+		query = query.Where("year = ?", *filter.Year)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Find(&books).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+func (r *GormBookRepository) Search(ctx context.Context, query string) ([]models.Book, error) {
+	var books []models.Book
+
+	err := r.scoped(ctx, ScopeActive).Where(clause.Or(
+		r.db.ContainsFold("title", query),
+		r.db.ContainsFold("author", query),
+		r.db.ContainsFold("description", query),
+	)).
+		Order("created_at DESC, id DESC").
+		Find(&books).Error
+
+	return books, err
+}
+
+func (r *GormBookRepository) ListAfter(ctx context.Context, id uint, limit int) ([]models.Book, error) {
+	var books []models.Book
+
+	err := r.scoped(ctx, ScopeActive).Where("id > ?", id).
+		Order("id ASC").
+		Limit(limit).
+		Find(&books).Error
+
+	return books, err
+}
+
+func (r *GormBookRepository) ListTrashed(ctx context.Context, limit, offset int) ([]models.Book, int64, error) {
+	var books []models.Book
+	var total int64
+
+	query := r.scoped(ctx, ScopeTrashed)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Order("deleted_at DESC, id DESC").Find(&books).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return books, total, nil
+}
+
+func (r *GormBookRepository) TrashedBefore(ctx context.Context, cutoff time.Time) ([]models.Book, error) {
+	var books []models.Book
+
+	err := r.scoped(ctx, ScopeTrashed).Where("deleted_at < ?", cutoff.UTC()).
+		Order("id ASC").
+		Find(&books).Error
+
+	return books, err
+}
+
+func (r *GormBookRepository) Create(ctx context.Context, book *models.Book) error {
+	return translateBookError(r.conn.WithContext(ctx).Create(book).Error)
+}
+
+func (r *GormBookRepository) Update(ctx context.Context, book *models.Book, version uint) error {
+	result := r.conn.WithContext(ctx).Model(book).Where("version = ?", version).Select("*").Updates(book)
+	return versionedResult(result)
+}
+
+func (r *GormBookRepository) Delete(ctx context.Context, book *models.Book) error {
+	result := r.conn.WithContext(ctx).Where("version = ?", book.Version).Delete(book)
+	return versionedResult(result)
+}
+
+func (r *GormBookRepository) Restore(ctx context.Context, book *models.Book) error {
+	version := book.Version + 1
+	updates := map[string]interface{}{
+		"deleted_at": nil,
+		"version":    version,
+	}
+
+	result := r.conn.WithContext(ctx).Unscoped().Model(book).
+		Where("deleted_at IS NOT NULL AND version = ?", book.Version).
+		Updates(updates)
+	if err := versionedResult(result); err != nil {
+		return err
+	}
+
+	book.DeletedAt = gorm.DeletedAt{}
+	book.Version = version
+	return nil
+}
+
+func (r *GormBookRepository) Purge(ctx context.Context, book *models.Book) error {
+	result := r.conn.WithContext(ctx).Unscoped().Where("version = ?", book.Version).Delete(book)
+	return versionedResult(result)
+}
+
+func (r *GormBookRepository) RecordAudit(ctx context.Context, event *models.AuditEvent) error {
+	return r.conn.WithContext(ctx).Create(event).Error
+}
+
+func (r *GormBookRepository) History(ctx context.Context, id uint) ([]models.AuditEvent, error) {
+	var events []models.AuditEvent
+
+	err := r.conn.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", models.AuditEntityBook, id).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+
+	return events, err
+}
+
+// versionedResult turns the result of a write conditional on the version of
+// a book into the error of the repository
+func versionedResult(result *gorm.DB) error {
+	if result.Error != nil {
+		return translateBookError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionMismatch
+	}
+	return nil
+}
+
+// translateBookError reports violations of the unique index on the ISBN of
+// active books as ErrDuplicateISBN
+func translateBookError(err error) error {
+	if err != nil && database.IsDuplicateKey(err) {
+		return ErrDuplicateISBN
+	}
+	return err
+}
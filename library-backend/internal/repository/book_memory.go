@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"library-backend/internal/models"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MemoryBookRepository stores books in memory. Transactions work on a copy
+// of the books and hold off every other access until they finish.
+type MemoryBookRepository struct {
+	mu   *sync.Mutex
+	data *memoryBooks
+}
+
+type memoryBooks struct {
+	books       map[uint]models.Book
+	events      []models.AuditEvent
+	lastID      uint
+	lastEventID uint
+}
+
+func NewMemoryBookRepository() *MemoryBookRepository {
+	return &MemoryBookRepository{
+		mu:   &sync.Mutex{},
+		data: &memoryBooks{books: make(map[uint]models.Book)},
+	}
+}
+
+func (r *MemoryBookRepository) Transaction(ctx context.Context, fn func(repo BookRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	staged := *r.data
+	staged.books = maps.Clone(r.data.books)
+	staged.events = slices.Clone(r.data.events)
+
+	// The transaction has the data to itself while r.mu is held
+	if err := fn(&MemoryBookRepository{mu: &sync.Mutex{}, data: &staged}); err != nil {
+		return err
+	}
+
+	*r.data = staged
+	return nil
+}
+
+func (r *MemoryBookRepository) Get(ctx context.Context, id uint, scope Scope) (*models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	book, ok := r.data.books[id]
+	if !ok || !inScope(&book, scope) {
+		return nil, ErrNotFound
+	}
+	return &book, nil
+}
+
+func (r *MemoryBookRepository) List(ctx context.Context, filter *models.BookFilter) ([]models.Book, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	books := r.data.find(func(book *models.Book) bool {
+		return inScope(book, ScopeActive) &&
+			containsFold(book.Title, filter.Title) &&
+			containsFold(book.Author, filter.Author) &&
+			(filter.Year == nil || book.Year == *filter.Year)
+	})
+	sortNewestFirst(books, func(book *models.Book) time.Time { return book.CreatedAt })
+
+	return page(books, filter.Limit, filter.Offset), int64(len(books)), nil
+}
+
+func (r *MemoryBookRepository) Search(ctx context.Context, query string) ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	books := r.data.find(func(book *models.Book) bool {
+		return inScope(book, ScopeActive) &&
+			(containsFold(book.Title, query) || containsFold(book.Author, query) || containsFold(book.Description, query))
+	})
+	sortNewestFirst(books, func(book *models.Book) time.Time { return book.CreatedAt })
+
+	return books, nil
+}
+
+func (r *MemoryBookRepository) ListAfter(ctx context.Context, id uint, limit int) ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	books := r.data.find(func(book *models.Book) bool {
+		return inScope(book, ScopeActive) && book.ID > id
+	})
+	slices.SortFunc(books, func(a, b models.Book) int { return cmp.Compare(a.ID, b.ID) })
+
+	return page(books, limit, 0), nil
+}
+
+func (r *MemoryBookRepository) ListTrashed(ctx context.Context, limit, offset int) ([]models.Book, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	books := r.data.find(func(book *models.Book) bool {
+		return inScope(book, ScopeTrashed)
+	})
+	sortNewestFirst(books, func(book *models.Book) time.Time { return book.DeletedAt.Time })
+
+	return page(books, limit, offset), int64(len(books)), nil
+}
+
+func (r *MemoryBookRepository) TrashedBefore(ctx context.Context, cutoff time.Time) ([]models.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	books := r.data.find(func(book *models.Book) bool {
+		return inScope(book, ScopeTrashed) && book.DeletedAt.Time.Before(cutoff)
+	})
+	slices.SortFunc(books, func(a, b models.Book) int { return cmp.Compare(a.ID, b.ID) })
+
+	return books, nil
+}
+
+func (r *MemoryBookRepository) Create(ctx context.Context, book *models.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.data.isbnTaken(book) {
+		return ErrDuplicateISBN
+	}
+
+	now := time.Now().UTC()
+	r.data.lastID++
+	book.ID = r.data.lastID
+	if book.CreatedAt.IsZero() {
+		book.CreatedAt = now
+	}
+	book.UpdatedAt = now
+	r.data.books[book.ID] = *book
+	return nil
+}
+
+func (r *MemoryBookRepository) Update(ctx context.Context, book *models.Book, version uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.data.books[book.ID]
+	if !ok || !inScope(&stored, ScopeActive) || stored.Version != version {
+		return ErrVersionMismatch
+	}
+	if r.data.isbnTaken(book) {
+		return ErrDuplicateISBN
+	}
+
+	book.UpdatedAt = time.Now().UTC()
+	r.data.books[book.ID] = *book
+	return nil
+}
+
+func (r *MemoryBookRepository) Delete(ctx context.Context, book *models.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.data.books[book.ID]
+	if !ok || !inScope(&stored, ScopeActive) || stored.Version != book.Version {
+		return ErrVersionMismatch
+	}
+
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	r.data.books[book.ID] = stored
+	book.DeletedAt = stored.DeletedAt
+	return nil
+}
+
+func (r *MemoryBookRepository) Restore(ctx context.Context, book *models.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.data.books[book.ID]
+	if !ok || !inScope(&stored, ScopeTrashed) || stored.Version != book.Version {
+		return ErrVersionMismatch
+	}
+	stored.DeletedAt = gorm.DeletedAt{}
+	if r.data.isbnTaken(&stored) {
+		return ErrDuplicateISBN
+	}
+
+	stored.Version++
+	stored.UpdatedAt = time.Now().UTC()
+	r.data.books[book.ID] = stored
+	book.DeletedAt = stored.DeletedAt
+	book.Version = stored.Version
+	return nil
+}
+
+func (r *MemoryBookRepository) Purge(ctx context.Context, book *models.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.data.books[book.ID]
+	if !ok || stored.Version != book.Version {
+		return ErrVersionMismatch
+	}
+
+	delete(r.data.books, book.ID)
+	return nil
+}
+
+func (r *MemoryBookRepository) RecordAudit(ctx context.Context, event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.data.lastEventID++
+	event.ID = r.data.lastEventID
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	r.data.events = append(r.data.events, *event)
+	return nil
+}
+
+func (r *MemoryBookRepository) History(ctx context.Context, id uint) ([]models.AuditEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []models.AuditEvent{}
+	for _, event := range r.data.events {
+		if event.EntityType == models.AuditEntityBook && event.EntityID == id {
+			events = append(events, event)
+		}
+	}
+	slices.SortStableFunc(events, func(a, b models.AuditEvent) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return events, nil
+}
+
+// find returns copies of the books matching match
+func (d *memoryBooks) find(match func(*models.Book) bool) []models.Book {
+	books := []models.Book{}
+	for _, book := range d.books {
+		if match(&book) {
+			books = append(books, book)
+		}
+	}
+	return books
+}
+
+// isbnTaken reports whether another active book has the ISBN of book, which
+// is about to be stored as active
+func (d *memoryBooks) isbnTaken(book *models.Book) bool {
+	if book.ISBN == "" {
+		return false
+	}
+	for id, other := range d.books {
+		if id != book.ID && inScope(&other, ScopeActive) && other.ISBN == book.ISBN {
+			return true
+		}
+	}
+	return false
+}
+
+func inScope(book *models.Book, scope Scope) bool {
+	switch scope {
+	case ScopeTrashed:
+		return book.DeletedAt.Valid
+	case ScopeAll:
+		return true
+	default:
+		return !book.DeletedAt.Valid
+	}
+}
+
+// containsFold reports whether s contains substr regardless of case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// sortNewestFirst orders books by descending time, then by descending ID
+func sortNewestFirst(books []models.Book, at func(*models.Book) time.Time) {
+	slices.SortFunc(books, func(a, b models.Book) int {
+		if c := at(&b).Compare(at(&a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+}
+
+// page returns the items of one page, all from offset if limit is not positive
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[max(offset, 0):]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,19 @@
+package repository_test
+
+import (
+	"library-backend/internal/repository"
+	"library-backend/internal/repository/repotest"
+	"testing"
+)
+
+func TestMemoryBookRepository(t *testing.T) {
+	repotest.TestBookRepository(t, func(t *testing.T) repository.BookRepository {
+		return repository.NewMemoryBookRepository()
+	})
+}
+
+func TestMemoryURLLogRepository(t *testing.T) {
+	repotest.TestURLLogRepository(t, func(t *testing.T) repository.URLLogRepository {
+		return repository.NewMemoryURLLogRepository()
+	})
+}
@@ -0,0 +1,114 @@
+// Package repository stores books and URL processing logs behind interfaces,
+// so the services run against the database or against memory. The GORM
+// implementations serve every database driver; the memory ones hold their
+// data in the process and suit tests and demos.
+package repository
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/models"
+	"time"
+)
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrVersionMismatch = errors.New("version mismatch")
+	ErrDuplicateISBN   = errors.New("isbn already in use by another book")
+)
+
+// Scope selects books by whether they are in the trash
+type Scope int
+
+const (
+	ScopeActive  Scope = iota // outside the trash
+	ScopeTrashed              // in the trash
+	ScopeAll
+)
+
+// BookRepository stores books and their audit trail. Writes conditional on a
+// version fail with ErrVersionMismatch when the stored book has another
+// version, and writes giving an active book the ISBN of another active book
+// fail with ErrDuplicateISBN.
+type BookRepository interface {
+	// Transaction runs fn with a repository whose writes are committed
+	// together when fn returns nil and discarded otherwise
+	Transaction(ctx context.Context, fn func(repo BookRepository) error) error
+
+	// Get returns the book with id in scope, or ErrNotFound
+	Get(ctx context.Context, id uint, scope Scope) (*models.Book, error)
+	// List returns one page of the active books matching filter, newest
+	// first, and the number of matches on all pages. Title and author match
+	// substrings regardless of case.
+	List(ctx context.Context, filter *models.BookFilter) ([]models.Book, int64, error)
+	// Search returns the active books whose title, author or description
+	// contains query regardless of case, newest first
+	Search(ctx context.Context, query string) ([]models.Book, error)
+	// ListAfter returns up to limit active books with an ID above id, in ID order
+	ListAfter(ctx context.Context, id uint, limit int) ([]models.Book, error)
+	// ListTrashed returns one page of the trash, most recently deleted first,
+	// and the number of books in the trash
+	ListTrashed(ctx context.Context, limit, offset int) ([]models.Book, int64, error)
+	// TrashedBefore returns the books moved to the trash before cutoff
+	TrashedBefore(ctx context.Context, cutoff time.Time) ([]models.Book, error)
+
+	// Create stores a new active book and sets its ID and timestamps
+	Create(ctx context.Context, book *models.Book) error
+	// Update stores every field of an active book if its stored version is
+	// version, and sets its update time
+	Update(ctx context.Context, book *models.Book, version uint) error
+	// Delete moves a book to the trash if its stored version is book.Version
+	Delete(ctx context.Context, book *models.Book) error
+	// Restore moves a book out of the trash if its stored version is
+	// book.Version, and increments its version
+	Restore(ctx context.Context, book *models.Book) error
+	// Purge removes a book, in the trash or not, if its stored version is
+	// book.Version
+	Purge(ctx context.Context, book *models.Book) error
+
+	// RecordAudit stores an audit event of a book and sets its ID and time
+	RecordAudit(ctx context.Context, event *models.AuditEvent) error
+	// History returns the audit events of a book, oldest first
+	History(ctx context.Context, id uint) ([]models.AuditEvent, error)
+}
+
+// URLLogCursor is the position of a log in the sort order of a URLLogQuery
+type URLLogCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// URLLogQuery selects URL processing logs
+type URLLogQuery struct {
+	Operation string
+	From      *time.Time
+	To        *time.Time
+	Domain    string        // host of the original URL, regardless of case
+	IPs       []string      // client addresses as stored; any of them matches
+	Sort      string        // one of models.URLLogSort*, newest first if empty
+	After     *URLLogCursor // only logs following this one
+	Limit     int
+}
+
+// URLLogRepository stores URL processing logs with their redirect hops, and
+// the daily counts kept when logs expire
+type URLLogRepository interface {
+	// Create stores new logs with their hops and sets their IDs and timestamps
+	Create(ctx context.Context, logs ...*models.URLProcessLog) error
+	// Get returns a log with its hops in order, or ErrNotFound
+	Get(ctx context.Context, id uint) (*models.URLProcessLog, error)
+	// List returns the logs matching query without their hops
+	List(ctx context.Context, query *URLLogQuery) ([]models.URLProcessLog, error)
+	// Stats summarizes the logs in the time range of filter. Series only
+	// holds the intervals with logs, and ChangedRatio is left to the caller.
+	Stats(ctx context.Context, filter *models.URLStatsFilter) (*models.URLStats, error)
+	// Expire removes the logs created before cutoff with their hops and
+	// returns how many logs were removed. With aggregate, their counts per
+	// day and operation are added to the aggregates first.
+	Expire(ctx context.Context, cutoff time.Time, aggregate bool) (int64, error)
+	// Aggregates returns the daily counts of expired logs by day and operation
+	Aggregates(ctx context.Context) ([]models.URLLogAggregate, error)
+	// DeleteByIP removes the logs of any of the client addresses ips with
+	// their hops and returns how many logs and hops were removed
+	DeleteByIP(ctx context.Context, ips []string) (logs, hops int64, err error)
+}
@@ -0,0 +1,431 @@
+// Package repotest checks that implementations of the repository interfaces
+// behave alike. Call its suites from the tests of each implementation:
+//
+//	func TestMemoryBookRepository(t *testing.T) {
+//		repotest.TestBookRepository(t, func(t *testing.T) repository.BookRepository {
+//			return repository.NewMemoryBookRepository()
+//		})
+//	}
+//
+// For the GORM implementations, newRepository returns a repository on a
+// freshly migrated database.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// TestBookRepository runs the conformance suite of BookRepository on the
+// empty repositories returned by newRepository
+func TestBookRepository(t *testing.T, newRepository func(t *testing.T) repository.BookRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.BookRepository)
+	}{
+		{"CreateAndGet", testBookCreateAndGet},
+		{"List", testBookList},
+		{"Search", testBookSearch},
+		{"ListAfter", testBookListAfter},
+		{"Update", testBookUpdate},
+		{"DeleteAndRestore", testBookDeleteAndRestore},
+		{"Purge", testBookPurge},
+		{"DuplicateISBN", testBookDuplicateISBN},
+		{"Transaction", testBookTransaction},
+		{"History", testBookHistory},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newRepository(t))
+		})
+	}
+}
+
+// createBook stores a new book and fails the test on error
+func createBook(t *testing.T, repo repository.BookRepository, title, author string, year int, isbn string) *models.Book {
+	t.Helper()
+
+	book := &models.Book{Title: title, Author: author, Year: year, ISBN: isbn, Version: 1}
+	if err := repo.Create(context.Background(), book); err != nil {
+		t.Fatalf("Create(%q): %v", title, err)
+	}
+	return book
+}
+
+// titles returns the titles of books in order
+func titles(books []models.Book) []string {
+	result := make([]string, 0, len(books))
+	for _, book := range books {
+		result = append(result, book.Title)
+	}
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func wantTitles(t *testing.T, call string, books []models.Book, want ...string) {
+	t.Helper()
+	if got := titles(books); !equalStrings(got, want) {
+		t.Errorf("%s = %q, want %q", call, got, want)
+	}
+}
+
+func wantError(t *testing.T, call string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Errorf("%s error = %v, want %v", call, err, want)
+	}
+}
+
+func testBookCreateAndGet(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	book := createBook(t, repo, "Dune", "Frank Herbert", 1965, "9780441013593")
+	if book.ID == 0 || book.CreatedAt.IsZero() || book.UpdatedAt.IsZero() {
+		t.Fatalf("Create did not set the ID and timestamps: %+v", book)
+	}
+	other := createBook(t, repo, "Emma", "Jane Austen", 1815, "")
+	if other.ID == book.ID {
+		t.Errorf("Create reused ID %d", book.ID)
+	}
+
+	got, err := repo.Get(ctx, book.ID, repository.ScopeActive)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Title != book.Title || got.Author != book.Author || got.Year != book.Year || got.ISBN != book.ISBN || got.Version != 1 {
+		t.Errorf("Get = %+v, want %+v", got, book)
+	}
+	if _, err := repo.Get(ctx, book.ID, repository.ScopeAll); err != nil {
+		t.Errorf("Get(ScopeAll): %v", err)
+	}
+
+	_, err = repo.Get(ctx, book.ID, repository.ScopeTrashed)
+	wantError(t, "Get(ScopeTrashed) of an active book", err, repository.ErrNotFound)
+	_, err = repo.Get(ctx, book.ID+other.ID, repository.ScopeAll)
+	wantError(t, "Get of a missing book", err, repository.ErrNotFound)
+}
+
+func testBookList(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	createBook(t, repo, "The Go Programming Language", "Alan Donovan", 2015, "")
+	createBook(t, repo, "Clean Code", "Robert C. Martin", 2008, "")
+	createBook(t, repo, "100% Go", "Gopher", 2020, "")
+	trashed := createBook(t, repo, "Go in Action", "William Kennedy", 2015, "")
+	if err := repo.Delete(ctx, trashed); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	year := 2015
+	tests := []struct {
+		filter models.BookFilter
+		want   []string
+		total  int64
+	}{
+		{models.BookFilter{}, []string{"100% Go", "Clean Code", "The Go Programming Language"}, 3},
+		{models.BookFilter{Title: "go"}, []string{"100% Go", "The Go Programming Language"}, 2},
+		{models.BookFilter{Title: "%"}, []string{"100% Go"}, 1},
+		{models.BookFilter{Title: "_"}, []string{}, 0},
+		{models.BookFilter{Author: "MARTIN"}, []string{"Clean Code"}, 1},
+		{models.BookFilter{Year: &year}, []string{"The Go Programming Language"}, 1},
+		{models.BookFilter{Limit: 2}, []string{"100% Go", "Clean Code"}, 3},
+		{models.BookFilter{Limit: 2, Offset: 2}, []string{"The Go Programming Language"}, 3},
+	}
+	for _, test := range tests {
+		books, total, err := repo.List(ctx, &test.filter)
+		if err != nil {
+			t.Fatalf("List(%+v): %v", test.filter, err)
+		}
+		wantTitles(t, "List", books, test.want...)
+		if total != test.total {
+			t.Errorf("List(%+v) total = %d, want %d", test.filter, total, test.total)
+		}
+	}
+}
+
+func testBookSearch(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	createBook(t, repo, "Dune", "Frank Herbert", 1965, "")
+	book := createBook(t, repo, "Emma", "Jane Austen", 1815, "")
+	book.Description = "A novel about youthful hubris"
+	if err := repo.Update(ctx, book, book.Version); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	createBook(t, repo, "Hyperion", "Dan Simmons", 1989, "")
+
+	for query, want := range map[string][]string{
+		"dune":    {"Dune"},
+		"AUSTEN":  {"Emma"},
+		"hubris":  {"Emma"},
+		"e":       {"Hyperion", "Emma", "Dune"},
+		"missing": {},
+	} {
+		books, err := repo.Search(ctx, query)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", query, err)
+		}
+		wantTitles(t, "Search("+query+")", books, want...)
+	}
+}
+
+func testBookListAfter(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	first := createBook(t, repo, "A", "Author", 2000, "")
+	trashed := createBook(t, repo, "B", "Author", 2000, "")
+	createBook(t, repo, "C", "Author", 2000, "")
+	createBook(t, repo, "D", "Author", 2000, "")
+	if err := repo.Delete(ctx, trashed); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	books, err := repo.ListAfter(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("ListAfter: %v", err)
+	}
+	wantTitles(t, "ListAfter(0, 2)", books, "A", "C")
+
+	books, err = repo.ListAfter(ctx, first.ID, 10)
+	if err != nil {
+		t.Fatalf("ListAfter: %v", err)
+	}
+	wantTitles(t, "ListAfter(first, 10)", books, "C", "D")
+}
+
+func testBookUpdate(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	book := createBook(t, repo, "Dune", "Frank Herbert", 1965, "")
+	book.Title = "Dune Messiah"
+	book.Year = 1969
+	book.Version = 2
+	if err := repo.Update(ctx, book, 1); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := repo.Get(ctx, book.ID, repository.ScopeActive)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Title != "Dune Messiah" || got.Year != 1969 || got.Version != 2 {
+		t.Errorf("Get after Update = %+v", got)
+	}
+
+	book.Version = 3
+	wantError(t, "Update with a stale version", repo.Update(ctx, book, 1), repository.ErrVersionMismatch)
+
+	book.Version = 2
+	if err := repo.Delete(ctx, book); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	book.Version = 3
+	wantError(t, "Update of a trashed book", repo.Update(ctx, book, 2), repository.ErrVersionMismatch)
+}
+
+func testBookDeleteAndRestore(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	book := createBook(t, repo, "Dune", "Frank Herbert", 1965, "")
+	older := createBook(t, repo, "Emma", "Jane Austen", 1815, "")
+	if err := repo.Delete(ctx, older); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	stale := *book
+	stale.Version = 7
+	wantError(t, "Delete with a stale version", repo.Delete(ctx, &stale), repository.ErrVersionMismatch)
+
+	before := time.Now()
+	if err := repo.Delete(ctx, book); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	wantError(t, "Delete of a trashed book", repo.Delete(ctx, book), repository.ErrVersionMismatch)
+
+	_, err := repo.Get(ctx, book.ID, repository.ScopeActive)
+	wantError(t, "Get(ScopeActive) of a trashed book", err, repository.ErrNotFound)
+	trashed, err := repo.Get(ctx, book.ID, repository.ScopeTrashed)
+	if err != nil {
+		t.Fatalf("Get(ScopeTrashed): %v", err)
+	}
+	if !trashed.DeletedAt.Valid || trashed.DeletedAt.Time.Before(before.Add(-time.Second)) {
+		t.Errorf("DeletedAt = %+v, want about %v", trashed.DeletedAt, before)
+	}
+
+	books, total, err := repo.ListTrashed(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("ListTrashed: %v", err)
+	}
+	wantTitles(t, "ListTrashed", books, "Dune", "Emma")
+	if total != 2 {
+		t.Errorf("ListTrashed total = %d, want 2", total)
+	}
+	books, _, err = repo.ListTrashed(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("ListTrashed: %v", err)
+	}
+	wantTitles(t, "ListTrashed(1, 1)", books, "Emma")
+
+	books, err = repo.TrashedBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("TrashedBefore: %v", err)
+	}
+	wantTitles(t, "TrashedBefore(now)", books, "Dune", "Emma")
+	books, err = repo.TrashedBefore(ctx, before.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("TrashedBefore: %v", err)
+	}
+	wantTitles(t, "TrashedBefore(an hour ago)", books)
+
+	if err := repo.Restore(ctx, trashed); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if trashed.Version != 2 || trashed.DeletedAt.Valid {
+		t.Errorf("Restore left %+v, want version 2 outside the trash", trashed)
+	}
+	restored, err := repo.Get(ctx, book.ID, repository.ScopeActive)
+	if err != nil {
+		t.Fatalf("Get after Restore: %v", err)
+	}
+	if restored.Version != 2 {
+		t.Errorf("Version after Restore = %d, want 2", restored.Version)
+	}
+	wantError(t, "Restore of an active book", repo.Restore(ctx, restored), repository.ErrVersionMismatch)
+}
+
+func testBookPurge(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	active := createBook(t, repo, "Dune", "Frank Herbert", 1965, "")
+	trashed := createBook(t, repo, "Emma", "Jane Austen", 1815, "")
+	if err := repo.Delete(ctx, trashed); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	stale := *active
+	stale.Version = 7
+	wantError(t, "Purge with a stale version", repo.Purge(ctx, &stale), repository.ErrVersionMismatch)
+
+	for _, book := range []*models.Book{active, trashed} {
+		if err := repo.Purge(ctx, book); err != nil {
+			t.Fatalf("Purge(%q): %v", book.Title, err)
+		}
+		_, err := repo.Get(ctx, book.ID, repository.ScopeAll)
+		wantError(t, "Get of a purged book", err, repository.ErrNotFound)
+	}
+	wantError(t, "Purge of a purged book", repo.Purge(ctx, active), repository.ErrVersionMismatch)
+}
+
+func testBookDuplicateISBN(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	const isbn = "9780441013593"
+
+	book := createBook(t, repo, "Dune", "Frank Herbert", 1965, isbn)
+	createBook(t, repo, "Emma", "Jane Austen", 1815, "")
+	createBook(t, repo, "Hyperion", "Dan Simmons", 1989, "")
+
+	duplicate := &models.Book{Title: "Dune", Author: "Frank Herbert", Year: 1965, ISBN: isbn, Version: 1}
+	wantError(t, "Create with a taken ISBN", repo.Create(ctx, duplicate), repository.ErrDuplicateISBN)
+
+	other := createBook(t, repo, "Dune Messiah", "Frank Herbert", 1969, "9780441172696")
+	other.ISBN = isbn
+	other.Version = 2
+	wantError(t, "Update to a taken ISBN", repo.Update(ctx, other, 1), repository.ErrDuplicateISBN)
+
+	// The ISBN of a book in the trash is free again, until the book is restored
+	if err := repo.Delete(ctx, book); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	createBook(t, repo, "Dune (reissue)", "Frank Herbert", 2005, isbn)
+	wantError(t, "Restore with a taken ISBN", repo.Restore(ctx, book), repository.ErrDuplicateISBN)
+}
+
+func testBookTransaction(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+	rollback := errors.New("rollback")
+
+	err := repo.Transaction(ctx, func(tx repository.BookRepository) error {
+		createBook(t, tx, "Discarded", "Author", 2000, "")
+		books, _, err := tx.List(ctx, &models.BookFilter{})
+		if err != nil {
+			return err
+		}
+		wantTitles(t, "List inside the transaction", books, "Discarded")
+		return rollback
+	})
+	wantError(t, "Transaction", err, rollback)
+
+	err = repo.Transaction(ctx, func(tx repository.BookRepository) error {
+		book := createBook(t, tx, "Kept", "Author", 2000, "")
+		return tx.RecordAudit(ctx, &models.AuditEvent{
+			EntityType: models.AuditEntityBook,
+			EntityID:   book.ID,
+			Action:     models.AuditActionCreate,
+			Actor:      "repotest",
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+
+	books, _, err := repo.List(ctx, &models.BookFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	wantTitles(t, "List after the transactions", books, "Kept")
+	events, err := repo.History(ctx, books[0].ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("History after the transaction has %d events, want 1", len(events))
+	}
+}
+
+func testBookHistory(t *testing.T, repo repository.BookRepository) {
+	ctx := context.Background()
+
+	book := createBook(t, repo, "Dune", "Frank Herbert", 1965, "")
+	events := []*models.AuditEvent{
+		{EntityType: models.AuditEntityBook, EntityID: book.ID, Action: models.AuditActionCreate, Actor: "repotest"},
+		{EntityType: models.AuditEntityURLRule, EntityID: book.ID, Action: models.AuditActionCreate, Actor: "repotest"},
+		{EntityType: models.AuditEntityBook, EntityID: book.ID + 1, Action: models.AuditActionCreate, Actor: "repotest"},
+		{EntityType: models.AuditEntityBook, EntityID: book.ID, Action: models.AuditActionUpdate, Actor: "repotest",
+			Changes: models.AuditChanges{"title": {Before: "Dune", After: "Dune Messiah"}}},
+	}
+	for _, event := range events {
+		if err := repo.RecordAudit(ctx, event); err != nil {
+			t.Fatalf("RecordAudit: %v", err)
+		}
+		if event.ID == 0 || event.CreatedAt.IsZero() {
+			t.Fatalf("RecordAudit did not set the ID and time: %+v", event)
+		}
+	}
+
+	history, err := repo.History(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 || history[0].Action != models.AuditActionCreate || history[1].Action != models.AuditActionUpdate {
+		t.Fatalf("History = %+v, want the create and the update of the book", history)
+	}
+	if change := history[1].Changes["title"]; change.After != "Dune Messiah" {
+		t.Errorf("History changes = %+v", history[1].Changes)
+	}
+}
@@ -0,0 +1,369 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"testing"
+	"time"
+)
+
+// Monday at 10:00, the time of the first log created by createLogs
+var logsStart = time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+
+// TestURLLogRepository runs the conformance suite of URLLogRepository on the
+// empty repositories returned by newRepository
+func TestURLLogRepository(t *testing.T, newRepository func(t *testing.T) repository.URLLogRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo repository.URLLogRepository)
+	}{
+		{"CreateAndGet", testURLLogCreateAndGet},
+		{"List", testURLLogList},
+		{"Stats", testURLLogStats},
+		{"Expire", testURLLogExpire},
+		{"DeleteByIP", testURLLogDeleteByIP},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, newRepository(t))
+		})
+	}
+}
+
+// createLogs stores four logs over nine days and returns them in creation
+// order. Only the third one, a resolve, has hops.
+func createLogs(t *testing.T, repo repository.URLLogRepository) []*models.URLProcessLog {
+	t.Helper()
+
+	logs := []*models.URLProcessLog{
+		{
+			OriginalURL:  "https://user@Example.com:8443/a?utm_source=x",
+			ProcessedURL: "https://example.com/a",
+			Operation:    "canonical",
+			IPAddress:    "10.0.0.1",
+			CreatedAt:    logsStart,
+		},
+		{
+			OriginalURL:  "http://example.com/b",
+			ProcessedURL: "http://example.com/b",
+			Operation:    "normalize",
+			IPAddress:    "10.0.0.2",
+			CreatedAt:    logsStart.Add(30 * time.Minute),
+		},
+		{
+			OriginalURL:  "https://go.dev/doc",
+			ProcessedURL: "https://go.dev/doc/",
+			Operation:    "resolve",
+			IPAddress:    "10.0.0.1",
+			CreatedAt:    logsStart.AddDate(0, 0, 1),
+			Hops: []models.URLRedirectHop{
+				{Position: 1, URL: "https://go.dev/doc/", Method: "HEAD", StatusCode: 200},
+				{Position: 0, URL: "https://go.dev/doc", Method: "HEAD", StatusCode: 301, Location: "https://go.dev/doc/"},
+			},
+		},
+		{
+			OriginalURL:  "not a url",
+			ProcessedURL: "not a url",
+			Operation:    "canonical",
+			CreatedAt:    logsStart.AddDate(0, 0, 8),
+		},
+	}
+
+	if err := repo.Create(context.Background(), logs...); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, log := range logs {
+		if log.ID == 0 {
+			t.Fatalf("Create did not set the ID of %q", log.OriginalURL)
+		}
+	}
+	return logs
+}
+
+// positions returns the positions in logs of the logs in got, by ID
+func positions(logs []*models.URLProcessLog, got []models.URLProcessLog) []int {
+	result := make([]int, 0, len(got))
+	for _, log := range got {
+		position := -1
+		for i, created := range logs {
+			if created.ID == log.ID {
+				position = i
+			}
+		}
+		result = append(result, position)
+	}
+	return result
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testURLLogCreateAndGet(t *testing.T, repo repository.URLLogRepository) {
+	ctx := context.Background()
+	logs := createLogs(t, repo)
+
+	if err := repo.Create(ctx); err != nil {
+		t.Errorf("Create without logs: %v", err)
+	}
+
+	resolved := logs[2]
+	got, err := repo.Get(ctx, resolved.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.OriginalURL != resolved.OriginalURL || got.Operation != "resolve" || got.IPAddress != "10.0.0.1" ||
+		!got.CreatedAt.Equal(resolved.CreatedAt) {
+		t.Errorf("Get = %+v, want %+v", got, resolved)
+	}
+	if len(got.Hops) != 2 || got.Hops[0].Position != 0 || got.Hops[1].Position != 1 {
+		t.Fatalf("Get hops = %+v, want positions 0 and 1", got.Hops)
+	}
+	if got.Hops[0].StatusCode != 301 || got.Hops[0].Location != "https://go.dev/doc/" || got.Hops[0].LogID != resolved.ID {
+		t.Errorf("Get first hop = %+v", got.Hops[0])
+	}
+
+	_, err = repo.Get(ctx, logs[3].ID+1)
+	wantError(t, "Get of a missing log", err, repository.ErrNotFound)
+}
+
+func testURLLogList(t *testing.T, repo repository.URLLogRepository) {
+	ctx := context.Background()
+	logs := createLogs(t, repo)
+
+	from := logsStart.Add(time.Hour)
+	to := logsStart.AddDate(0, 0, 8)
+	tests := []struct {
+		name  string
+		query repository.URLLogQuery
+		want  []int
+	}{
+		{"newest first", repository.URLLogQuery{}, []int{3, 2, 1, 0}},
+		{"oldest first", repository.URLLogQuery{Sort: models.URLLogSortCreatedAt}, []int{0, 1, 2, 3}},
+		{"by ID", repository.URLLogQuery{Sort: models.URLLogSortID}, []int{0, 1, 2, 3}},
+		{"by descending ID", repository.URLLogQuery{Sort: models.URLLogSortIDDesc}, []int{3, 2, 1, 0}},
+		{"operation", repository.URLLogQuery{Operation: "canonical"}, []int{3, 0}},
+		{"domain", repository.URLLogQuery{Domain: "EXAMPLE.com"}, []int{1, 0}},
+		{"IPs", repository.URLLogQuery{IPs: []string{"10.0.0.1", "10.9.9.9"}}, []int{2, 0}},
+		{"time range", repository.URLLogQuery{From: &from, To: &to}, []int{2}},
+		{"limit", repository.URLLogQuery{Limit: 2}, []int{3, 2}},
+		{"after, newest first", repository.URLLogQuery{
+			After: &repository.URLLogCursor{CreatedAt: logs[2].CreatedAt, ID: logs[2].ID},
+		}, []int{1, 0}},
+		{"after, oldest first", repository.URLLogQuery{
+			Sort:  models.URLLogSortCreatedAt,
+			After: &repository.URLLogCursor{CreatedAt: logs[1].CreatedAt, ID: logs[1].ID},
+			Limit: 1,
+		}, []int{2}},
+		{"after, by ID", repository.URLLogQuery{Sort: models.URLLogSortID, After: &repository.URLLogCursor{ID: logs[1].ID}}, []int{2, 3}},
+		{"after, by descending ID", repository.URLLogQuery{Sort: models.URLLogSortIDDesc, After: &repository.URLLogCursor{ID: logs[1].ID}}, []int{0}},
+	}
+
+	for _, test := range tests {
+		got, err := repo.List(ctx, &test.query)
+		if err != nil {
+			t.Fatalf("List(%s): %v", test.name, err)
+		}
+		if positions := positions(logs, got); !equalInts(positions, test.want) {
+			t.Errorf("List(%s) = logs %v, want %v", test.name, positions, test.want)
+		}
+		for _, log := range got {
+			if len(log.Hops) != 0 {
+				t.Errorf("List(%s) returned the hops of log %d", test.name, log.ID)
+			}
+		}
+	}
+}
+
+func testURLLogStats(t *testing.T, repo repository.URLLogRepository) {
+	ctx := context.Background()
+	createLogs(t, repo)
+
+	day := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+	stats, err := repo.Stats(ctx, &models.URLStatsFilter{Interval: models.StatsIntervalDay, Top: 1})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalRequests != 4 || stats.ChangedRequests != 2 || stats.UniqueClientIPs != 2 {
+		t.Errorf("Stats totals = %d requests, %d changed, %d IPs, want 4, 2 and 2",
+			stats.TotalRequests, stats.ChangedRequests, stats.UniqueClientIPs)
+	}
+	if len(stats.ByOperation) != 3 || stats.ByOperation["canonical"] != 2 || stats.ByOperation["normalize"] != 1 || stats.ByOperation["resolve"] != 1 {
+		t.Errorf("Stats by operation = %v", stats.ByOperation)
+	}
+	wantSeries(t, "daily", stats.Series, day, 2, day.AddDate(0, 0, 1), 1, day.AddDate(0, 0, 8), 1)
+	if len(stats.TopDomains) != 1 || stats.TopDomains[0] != (models.URLStatsCount{Value: "example.com", Count: 2}) {
+		t.Errorf("Stats top domains = %+v, want example.com twice", stats.TopDomains)
+	}
+	// Ties are broken by value
+	if len(stats.TopProcessedURLs) != 1 || stats.TopProcessedURLs[0] != (models.URLStatsCount{Value: "http://example.com/b", Count: 1}) {
+		t.Errorf("Stats top processed URLs = %+v, want http://example.com/b once", stats.TopProcessedURLs)
+	}
+
+	stats, err = repo.Stats(ctx, &models.URLStatsFilter{Interval: models.StatsIntervalWeek, Top: 10})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	wantSeries(t, "weekly", stats.Series, day, 3, day.AddDate(0, 0, 7), 1)
+	if len(stats.TopProcessedURLs) != 4 {
+		t.Errorf("Stats top processed URLs = %+v, want 4 URLs", stats.TopProcessedURLs)
+	}
+
+	from := logsStart.Add(time.Minute)
+	to := logsStart.AddDate(0, 0, 2)
+	stats, err = repo.Stats(ctx, &models.URLStatsFilter{From: &from, To: &to, Interval: models.StatsIntervalHour, Top: 10})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalRequests != 2 || stats.ChangedRequests != 1 || stats.UniqueClientIPs != 2 {
+		t.Errorf("Stats totals in range = %d requests, %d changed, %d IPs, want 2, 1 and 2",
+			stats.TotalRequests, stats.ChangedRequests, stats.UniqueClientIPs)
+	}
+	wantSeries(t, "hourly", stats.Series, logsStart, 1, logsStart.AddDate(0, 0, 1), 1)
+
+	empty := logsStart.AddDate(1, 0, 0)
+	stats, err = repo.Stats(ctx, &models.URLStatsFilter{From: &empty, Interval: models.StatsIntervalDay, Top: 10})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.TotalRequests != 0 || len(stats.Series) != 0 || len(stats.TopDomains) != 0 || len(stats.TopProcessedURLs) != 0 {
+		t.Errorf("Stats without logs = %+v", stats)
+	}
+}
+
+// wantSeries checks series against pairs of bucket start and count
+func wantSeries(t *testing.T, name string, series []models.URLStatsBucket, want ...interface{}) {
+	t.Helper()
+
+	describe := func() string {
+		description := ""
+		for _, bucket := range series {
+			description += fmt.Sprintf(" %s=%d", bucket.Start.UTC().Format(time.RFC3339), bucket.Count)
+		}
+		return description
+	}
+	if len(series) != len(want)/2 {
+		t.Errorf("Stats %s series:%s, want %d buckets", name, describe(), len(want)/2)
+		return
+	}
+	for i, bucket := range series {
+		start, count := want[2*i].(time.Time), want[2*i+1].(int)
+		if !bucket.Start.Equal(start) || bucket.Count != int64(count) {
+			t.Errorf("Stats %s series:%s, want %s=%d at %d", name, describe(), start.Format(time.RFC3339), count, i)
+		}
+	}
+}
+
+func testURLLogExpire(t *testing.T, repo repository.URLLogRepository) {
+	ctx := context.Background()
+	logs := createLogs(t, repo)
+	day := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	removed, err := repo.Expire(ctx, logsStart.AddDate(0, 0, 2), true)
+	if err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+	if removed != 3 {
+		t.Errorf("Expire removed %d logs, want 3", removed)
+	}
+	for _, log := range logs[:3] {
+		_, err := repo.Get(ctx, log.ID)
+		wantError(t, "Get of an expired log", err, repository.ErrNotFound)
+	}
+	if _, err := repo.Get(ctx, logs[3].ID); err != nil {
+		t.Errorf("Get of a log after the cutoff: %v", err)
+	}
+
+	// A later expiry adds to the counts of the same day and operation
+	late := &models.URLProcessLog{
+		OriginalURL:  "https://example.com/c",
+		ProcessedURL: "https://example.com/c",
+		Operation:    "canonical",
+		CreatedAt:    logsStart.Add(2 * time.Hour),
+	}
+	if err := repo.Create(ctx, late); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.Expire(ctx, logsStart.AddDate(0, 0, 2), true); err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+
+	want := []models.URLLogAggregate{
+		{Day: day, Operation: "canonical", Requests: 2, Changed: 1},
+		{Day: day, Operation: "normalize", Requests: 1, Changed: 0},
+		{Day: day.AddDate(0, 0, 1), Operation: "resolve", Requests: 1, Changed: 1},
+	}
+	wantAggregates(t, repo, want)
+
+	removed, err = repo.Expire(ctx, logsStart.AddDate(1, 0, 0), false)
+	if err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expire without aggregation removed %d logs, want 1", removed)
+	}
+	wantAggregates(t, repo, want)
+
+	removed, err = repo.Expire(ctx, logsStart.AddDate(1, 0, 0), true)
+	if err != nil || removed != 0 {
+		t.Errorf("Expire without logs = %d, %v, want 0", removed, err)
+	}
+}
+
+func wantAggregates(t *testing.T, repo repository.URLLogRepository, want []models.URLLogAggregate) {
+	t.Helper()
+
+	aggregates, err := repo.Aggregates(context.Background())
+	if err != nil {
+		t.Fatalf("Aggregates: %v", err)
+	}
+	if len(aggregates) != len(want) {
+		t.Fatalf("Aggregates = %+v, want %+v", aggregates, want)
+	}
+	for i, aggregate := range aggregates {
+		if !aggregate.Day.Equal(want[i].Day) || aggregate.Operation != want[i].Operation ||
+			aggregate.Requests != want[i].Requests || aggregate.Changed != want[i].Changed {
+			t.Errorf("Aggregates[%d] = %+v, want %+v", i, aggregate, want[i])
+		}
+	}
+}
+
+func testURLLogDeleteByIP(t *testing.T, repo repository.URLLogRepository) {
+	ctx := context.Background()
+	logs := createLogs(t, repo)
+
+	deleted, hops, err := repo.DeleteByIP(ctx, []string{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("DeleteByIP: %v", err)
+	}
+	if deleted != 2 || hops != 2 {
+		t.Errorf("DeleteByIP deleted %d logs and %d hops, want 2 and 2", deleted, hops)
+	}
+
+	remaining, err := repo.List(ctx, &repository.URLLogQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := positions(logs, remaining); !equalInts(got, []int{3, 1}) {
+		t.Errorf("List after DeleteByIP = logs %v, want [3 1]", got)
+	}
+
+	deleted, hops, err = repo.DeleteByIP(ctx, []string{"10.0.0.1", "10.9.9.9"})
+	if err != nil {
+		t.Fatalf("DeleteByIP: %v", err)
+	}
+	if deleted != 0 || hops != 0 {
+		t.Errorf("DeleteByIP of unknown addresses deleted %d logs and %d hops", deleted, hops)
+	}
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/pkg/database"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Extracts the lowercased host of original_url, without user info or port
+const originalDomainExpr = `lower(substring(original_url from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?(\[[^]]*\]|[^:/?#]*)'))`
+
+// originalDomainExpr for SQLite, which has no regular expressions. Each
+// nested query strips one part of the URL: the scheme, then the path, query
+// and fragment, then the user info and finally the port.
+const sqliteOriginalDomainExpr = `(SELECT lower(CASE WHEN substr(host, 1, 1) = '[' THEN substr(host, 1, instr(host, ']')) ` +
+	`ELSE substr(host, 1, instr(host || ':', ':') - 1) END) ` +
+	`FROM (SELECT substr(authority, instr(authority, '@') + 1) AS host ` +
+	`FROM (SELECT substr(rest, 1, min(instr(rest || '/', '/'), instr(rest || '?', '?'), instr(rest || '#', '#')) - 1) AS authority ` +
+	`FROM (SELECT substr(original_url, instr(original_url, '://') + 3) AS rest WHERE instr(original_url, '://') > 0))))`
+
+// Number of log rows written per INSERT statement by Create
+const urlLogInsertBatchSize = 500
+
+// GormURLLogRepository stores URL processing logs in the database
+type GormURLLogRepository struct {
+	db *database.Database
+}
+
+func NewGormURLLogRepository(db *database.Database) *GormURLLogRepository {
+	return &GormURLLogRepository{db: db}
+}
+
+// originalDomain returns the expression extracting the host of original_url
+// in the dialect of the database
+func (r *GormURLLogRepository) originalDomain() string {
+	if r.db.Driver() == database.DriverSQLite {
+		return sqliteOriginalDomainExpr
+	}
+	return originalDomainExpr
+}
+
+func (r *GormURLLogRepository) Create(ctx context.Context, logs ...*models.URLProcessLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(logs, urlLogInsertBatchSize).Error
+}
+
+func (r *GormURLLogRepository) Get(ctx context.Context, id uint) (*models.URLProcessLog, error) {
+	var log models.URLProcessLog
+
+	err := r.db.WithContext(ctx).Preload("Hops", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).First(&log, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &log, nil
+}
+
+func (r *GormURLLogRepository) List(ctx context.Context, query *URLLogQuery) ([]models.URLProcessLog, error) {
+	db := r.db.WithContext(ctx).Model(&models.URLProcessLog{})
+
+	if query.Operation != "" {
+		db = db.Where("operation = ?", query.Operation)
+	}
+	db = inTimeRange(db, query.From, query.To)
+	if query.Domain != "" {
+		db = db.Where(r.originalDomain()+" = ?", strings.ToLower(query.Domain))
+	}
+	if len(query.IPs) > 0 {
+		db = db.Where("ip_address IN ?", query.IPs)
+	}
+
+	switch query.Sort {
+	case models.URLLogSortCreatedAt:
+		db = db.Order("created_at ASC, id ASC")
+	case models.URLLogSortID:
+		db = db.Order("id ASC")
+	case models.URLLogSortIDDesc:
+		db = db.Order("id DESC")
+	default:
+		db = db.Order("created_at DESC, id DESC")
+	}
+
+	if after := query.After; after != nil {
+		switch query.Sort {
+		case models.URLLogSortCreatedAt:
+			db = db.Where("created_at > ? OR (created_at = ? AND id > ?)", after.CreatedAt, after.CreatedAt, after.ID)
+		case models.URLLogSortID:
+			db = db.Where("id > ?", after.ID)
+		case models.URLLogSortIDDesc:
+			db = db.Where("id < ?", after.ID)
+		default:
+			db = db.Where("created_at < ? OR (created_at = ? AND id < ?)", after.CreatedAt, after.CreatedAt, after.ID)
+		}
+	}
+
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	logs := []models.URLProcessLog{}
+	err := db.Find(&logs).Error
+	return logs, err
+}
+
+// inTimeRange restricts db to the logs created in [from, to)
+func inTimeRange(db *gorm.DB, from, to *time.Time) *gorm.DB {
+	// Timestamps are stored in UTC, and SQLite compares them as text
+	if from != nil {
+		db = db.Where("created_at >= ?", from.UTC())
+	}
+	if to != nil {
+		db = db.Where("created_at < ?", to.UTC())
+	}
+	return db
+}
+
+func (r *GormURLLogRepository) Stats(ctx context.Context, filter *models.URLStatsFilter) (*models.URLStats, error) {
+	stats := &models.URLStats{ByOperation: make(map[string]int64)}
+	query := func() *gorm.DB {
+		return inTimeRange(r.db.WithContext(ctx).Model(&models.URLProcessLog{}), filter.From, filter.To)
+	}
+
+	// Totals
+	var totals struct {
+		Total     int64
+		Changed   int64
+		UniqueIPs int64
+	}
+	err := query().
+		Select("COUNT(*) AS total, " +
+			"COALESCE(SUM(CASE WHEN processed_url <> original_url THEN 1 ELSE 0 END), 0) AS changed, " +
+			"COUNT(DISTINCT NULLIF(ip_address, '')) AS unique_ips").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.TotalRequests = totals.Total
+	stats.ChangedRequests = totals.Changed
+	stats.UniqueClientIPs = totals.UniqueIPs
+
+	// By operation
+	var operationStats []struct {
+		Operation string
+		Count     int64
+	}
+	err = query().
+		Select("operation, COUNT(*) AS count").
+		Group("operation").
+		Scan(&operationStats).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range operationStats {
+		stats.ByOperation[stat.Operation] = stat.Count
+	}
+
+	// Time series
+	var rows []struct {
+		Start database.Time
+		Count int64
+	}
+	err = query().
+		Select("? AS start, COUNT(*) AS count", r.db.TruncateTime(filter.Interval, "created_at")).
+		Group("start").
+		Order("start ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Series = make([]models.URLStatsBucket, 0, len(rows))
+	for _, row := range rows {
+		stats.Series = append(stats.Series, models.URLStatsBucket{Start: row.Start.Time, Count: row.Count})
+	}
+
+	// Top lists
+	top := func(expr string) ([]models.URLStatsCount, error) {
+		counts := []models.URLStatsCount{}
+		err := query().
+			Select(expr + " AS value, COUNT(*) AS count").
+			Where(expr + " <> ''").
+			Group("value").
+			Order("count DESC, value ASC").
+			Limit(filter.Top).
+			Scan(&counts).Error
+		return counts, err
+	}
+	if stats.TopDomains, err = top(r.originalDomain()); err != nil {
+		return nil, err
+	}
+	if stats.TopProcessedURLs, err = top("processed_url"); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *GormURLLogRepository) Expire(ctx context.Context, cutoff time.Time, aggregate bool) (int64, error) {
+	var removed int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&models.URLProcessLog{}).Where("created_at < ?", cutoff.UTC())
+
+		if aggregate {
+			var rows []struct {
+				Day       database.Time
+				Operation string
+				Requests  int64
+				Changed   int64
+			}
+			err := expired.Session(&gorm.Session{}).
+				Select("? AS day, operation, COUNT(*) AS requests, "+
+					"SUM(CASE WHEN processed_url <> original_url THEN 1 ELSE 0 END) AS changed", r.db.TruncateTime("day", "created_at")).
+				Group("day, operation").
+				Scan(&rows).Error
+			if err != nil {
+				return err
+			}
+
+			aggregates := make([]models.URLLogAggregate, 0, len(rows))
+			for _, row := range rows {
+				aggregates = append(aggregates, models.URLLogAggregate{
+					Day:       row.Day.Time,
+					Operation: row.Operation,
+					Requests:  row.Requests,
+					Changed:   row.Changed,
+				})
+			}
+
+			if len(aggregates) > 0 {
+				err = tx.Clauses(clause.OnConflict{
+					Columns: []clause.Column{{Name: "day"}, {Name: "operation"}},
+					DoUpdates: clause.Assignments(map[string]interface{}{
+						"requests":   gorm.Expr("url_log_aggregates.requests + excluded.requests"),
+						"changed":    gorm.Expr("url_log_aggregates.changed + excluded.changed"),
+						"updated_at": gorm.Expr("excluded.updated_at"),
+					}),
+				}).Create(&aggregates).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		expiredIDs := expired.Session(&gorm.Session{}).Select("id")
+		if err := tx.Where("log_id IN (?)", expiredIDs).Delete(&models.URLRedirectHop{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("created_at < ?", cutoff.UTC()).Delete(&models.URLProcessLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = result.RowsAffected
+		return nil
+	})
+
+	return removed, err
+}
+
+func (r *GormURLLogRepository) Aggregates(ctx context.Context) ([]models.URLLogAggregate, error) {
+	aggregates := []models.URLLogAggregate{}
+	err := r.db.WithContext(ctx).Order("day ASC, operation ASC").Find(&aggregates).Error
+	return aggregates, err
+}
+
+func (r *GormURLLogRepository) DeleteByIP(ctx context.Context, ips []string) (logs, hops int64, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		logIDs := tx.Unscoped().Model(&models.URLProcessLog{}).Select("id").Where("ip_address IN ?", ips)
+
+		result := tx.Where("log_id IN (?)", logIDs).Delete(&models.URLRedirectHop{})
+		if result.Error != nil {
+			return result.Error
+		}
+		hops = result.RowsAffected
+
+		result = tx.Unscoped().Where("ip_address IN ?", ips).Delete(&models.URLProcessLog{})
+		if result.Error != nil {
+			return result.Error
+		}
+		logs = result.RowsAffected
+		return nil
+	})
+
+	return logs, hops, err
+}
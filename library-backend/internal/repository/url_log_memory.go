@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"library-backend/internal/models"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// originalDomainPattern matches the host of a URL as originalDomainExpr does
+var originalDomainPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?(\[[^\]]*\]|[^:/?#]*)`)
+
+// MemoryURLLogRepository stores URL processing logs in memory
+type MemoryURLLogRepository struct {
+	mu         sync.Mutex
+	logs       map[uint]models.URLProcessLog
+	aggregates []models.URLLogAggregate
+	lastID     uint
+	lastHopID  uint
+	lastAggID  uint
+}
+
+func NewMemoryURLLogRepository() *MemoryURLLogRepository {
+	return &MemoryURLLogRepository{logs: make(map[uint]models.URLProcessLog)}
+}
+
+func (r *MemoryURLLogRepository) Create(ctx context.Context, logs ...*models.URLProcessLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, log := range logs {
+		r.lastID++
+		log.ID = r.lastID
+		if log.CreatedAt.IsZero() {
+			log.CreatedAt = now
+		}
+		log.UpdatedAt = now
+
+		for i := range log.Hops {
+			r.lastHopID++
+			log.Hops[i].ID = r.lastHopID
+			log.Hops[i].LogID = log.ID
+			if log.Hops[i].CreatedAt.IsZero() {
+				log.Hops[i].CreatedAt = now
+			}
+		}
+
+		stored := *log
+		stored.Hops = slices.Clone(log.Hops)
+		r.logs[log.ID] = stored
+	}
+	return nil
+}
+
+func (r *MemoryURLLogRepository) Get(ctx context.Context, id uint) (*models.URLProcessLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.logs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	log := stored
+	log.Hops = slices.Clone(stored.Hops)
+	slices.SortStableFunc(log.Hops, func(a, b models.URLRedirectHop) int { return cmp.Compare(a.Position, b.Position) })
+	return &log, nil
+}
+
+func (r *MemoryURLLogRepository) List(ctx context.Context, query *URLLogQuery) ([]models.URLProcessLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	domain := strings.ToLower(query.Domain)
+	logs := r.find(func(log *models.URLProcessLog) bool {
+		return (query.Operation == "" || log.Operation == query.Operation) &&
+			inTimeRangeOf(log.CreatedAt, query.From, query.To) &&
+			(domain == "" || originalDomain(log.OriginalURL) == domain) &&
+			(len(query.IPs) == 0 || slices.Contains(query.IPs, log.IPAddress))
+	})
+
+	// compare orders two logs as the sort of the query does
+	compare := func(a, b *models.URLProcessLog) int {
+		switch query.Sort {
+		case models.URLLogSortCreatedAt:
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.ID, b.ID)
+		case models.URLLogSortID:
+			return cmp.Compare(a.ID, b.ID)
+		case models.URLLogSortIDDesc:
+			return cmp.Compare(b.ID, a.ID)
+		default:
+			if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+				return c
+			}
+			return cmp.Compare(b.ID, a.ID)
+		}
+	}
+	slices.SortFunc(logs, func(a, b models.URLProcessLog) int { return compare(&a, &b) })
+
+	if after := query.After; after != nil {
+		cursor := &models.URLProcessLog{ID: after.ID, CreatedAt: after.CreatedAt}
+		logs = slices.DeleteFunc(logs, func(log models.URLProcessLog) bool { return compare(&log, cursor) <= 0 })
+	}
+
+	return page(logs, query.Limit, 0), nil
+}
+
+func (r *MemoryURLLogRepository) Stats(ctx context.Context, filter *models.URLStatsFilter) (*models.URLStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	logs := r.find(func(log *models.URLProcessLog) bool {
+		return inTimeRangeOf(log.CreatedAt, filter.From, filter.To)
+	})
+
+	stats := &models.URLStats{ByOperation: make(map[string]int64)}
+	ips := make(map[string]bool)
+	buckets := make(map[time.Time]int64)
+	domains := make(map[string]int64)
+	processed := make(map[string]int64)
+	for _, log := range logs {
+		stats.TotalRequests++
+		if log.ProcessedURL != log.OriginalURL {
+			stats.ChangedRequests++
+		}
+		if log.IPAddress != "" {
+			ips[log.IPAddress] = true
+		}
+		stats.ByOperation[log.Operation]++
+		buckets[truncateTime(filter.Interval, log.CreatedAt)]++
+		if domain := originalDomain(log.OriginalURL); domain != "" {
+			domains[domain]++
+		}
+		if log.ProcessedURL != "" {
+			processed[log.ProcessedURL]++
+		}
+	}
+	stats.UniqueClientIPs = int64(len(ips))
+
+	stats.Series = make([]models.URLStatsBucket, 0, len(buckets))
+	for start, count := range buckets {
+		stats.Series = append(stats.Series, models.URLStatsBucket{Start: start, Count: count})
+	}
+	slices.SortFunc(stats.Series, func(a, b models.URLStatsBucket) int { return a.Start.Compare(b.Start) })
+
+	stats.TopDomains = topCounts(domains, filter.Top)
+	stats.TopProcessedURLs = topCounts(processed, filter.Top)
+
+	return stats, nil
+}
+
+func (r *MemoryURLLogRepository) Expire(ctx context.Context, cutoff time.Time, aggregate bool) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type aggregateKey struct {
+		day       time.Time
+		operation string
+	}
+	now := time.Now().UTC()
+	added := make(map[aggregateKey]*models.URLLogAggregate)
+
+	var removed int64
+	for id, log := range r.logs {
+		if !log.CreatedAt.Before(cutoff) {
+			continue
+		}
+		delete(r.logs, id)
+		removed++
+
+		if !aggregate {
+			continue
+		}
+		key := aggregateKey{day: truncateTime(models.StatsIntervalDay, log.CreatedAt), operation: log.Operation}
+		if added[key] == nil {
+			added[key] = &models.URLLogAggregate{Day: key.day, Operation: key.operation}
+		}
+		added[key].Requests++
+		if log.ProcessedURL != log.OriginalURL {
+			added[key].Changed++
+		}
+	}
+
+	for key, counts := range added {
+		i := slices.IndexFunc(r.aggregates, func(existing models.URLLogAggregate) bool {
+			return existing.Day.Equal(key.day) && existing.Operation == key.operation
+		})
+		if i >= 0 {
+			r.aggregates[i].Requests += counts.Requests
+			r.aggregates[i].Changed += counts.Changed
+			r.aggregates[i].UpdatedAt = now
+			continue
+		}
+
+		r.lastAggID++
+		counts.ID = r.lastAggID
+		counts.CreatedAt = now
+		counts.UpdatedAt = now
+		r.aggregates = append(r.aggregates, *counts)
+	}
+
+	return removed, nil
+}
+
+func (r *MemoryURLLogRepository) Aggregates(ctx context.Context) ([]models.URLLogAggregate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	aggregates := slices.Clone(r.aggregates)
+	slices.SortFunc(aggregates, func(a, b models.URLLogAggregate) int {
+		if c := a.Day.Compare(b.Day); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Operation, b.Operation)
+	})
+	if aggregates == nil {
+		aggregates = []models.URLLogAggregate{}
+	}
+	return aggregates, nil
+}
+
+func (r *MemoryURLLogRepository) DeleteByIP(ctx context.Context, ips []string) (logs, hops int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, log := range r.logs {
+		if slices.Contains(ips, log.IPAddress) {
+			delete(r.logs, id)
+			logs++
+			hops += int64(len(log.Hops))
+		}
+	}
+	return logs, hops, nil
+}
+
+// find returns copies of the logs matching match, without their hops
+func (r *MemoryURLLogRepository) find(match func(*models.URLProcessLog) bool) []models.URLProcessLog {
+	logs := []models.URLProcessLog{}
+	for _, log := range r.logs {
+		if match(&log) {
+			log.Hops = nil
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// inTimeRangeOf reports whether t is in [from, to)
+func inTimeRangeOf(t time.Time, from, to *time.Time) bool {
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
+
+// originalDomain returns the lowercased host of rawURL, without user info or
+// port, or "" if rawURL has no scheme
+func originalDomain(rawURL string) string {
+	match := originalDomainPattern.FindStringSubmatch(rawURL)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// truncateTime returns the start of the hour, day or week (starting on
+// Monday) of t in UTC
+func truncateTime(unit string, t time.Time) time.Time {
+	t = t.UTC()
+	switch unit {
+	case models.StatsIntervalHour:
+		return t.Truncate(time.Hour)
+	case models.StatsIntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// topCounts returns the limit most frequent values of counts, ties in value order
+func topCounts(counts map[string]int64, limit int) []models.URLStatsCount {
+	top := make([]models.URLStatsCount, 0, len(counts))
+	for value, count := range counts {
+		top = append(top, models.URLStatsCount{Value: value, Count: count})
+	}
+	slices.SortFunc(top, func(a, b models.URLStatsCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return page(top, limit, 0)
+}
@@ -0,0 +1,82 @@
+package requestctx
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type contextKey string
+
+const (
+	actorKey        contextKey = "actor"
+	claimedActorKey contextKey = "claimed_actor"
+	requestIDKey    contextKey = "request_id"
+	clientIPKey     contextKey = "client_ip"
+	loggerKey       contextKey = "logger"
+)
+
+// AnonymousActor is recorded when a request carries no identity
+const AnonymousActor = "anonymous"
+
+// WithActor returns a copy of ctx carrying the acting user
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// Actor returns the acting user, or AnonymousActor if none is set
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// WithClaimedActor returns a copy of ctx carrying the actor named by the
+// client, which nothing has verified
+func WithClaimedActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, claimedActorKey, actor)
+}
+
+// ClaimedActor returns the unverified actor named by the client, or an empty
+// string if none is set
+func ClaimedActor(ctx context.Context) string {
+	actor, _ := ctx.Value(claimedActorKey).(string)
+	return actor
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID, or an empty string if none is set
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithClientIP returns a copy of ctx carrying the client IP address
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIP returns the client IP address, or an empty string if none is set
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// WithLogger returns a copy of ctx carrying a logger scoped to the request or
+// job, e.g. with its request ID already set as a field
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// Logger returns the scoped logger, or the standard logrus logger if none is set
+func Logger(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey).(*logrus.Entry); ok {
+		return logger
+	}
+	return logrus.NewEntry(logrus.StandardLogger()).WithContext(ctx)
+}
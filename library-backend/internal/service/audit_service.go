@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"library-backend/internal/models"
+	"library-backend/internal/requestctx"
+	"library-backend/pkg/database"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Fields that change on every write and carry no information for the audit trail
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+}
+
+type AuditService struct {
+	db *database.Database
+}
+
+func NewAuditService(db *database.Database) *AuditService {
+	return &AuditService{db: db}
+}
+
+func (s *AuditService) QueryEvents(ctx context.Context, filter *models.AuditFilter) (*models.AuditEventsResponse, error) {
+	var events []models.AuditEvent
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.AuditEvent{})
+
+	// Apply filters
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		query = query.Where("entity_id = ?", *filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// Apply pagination
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.AuditEventsResponse{
+		Success: true,
+		Data:    events,
+		Total:   total,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// recordAudit writes an audit event for an entity change using tx, so the event
+// commits or rolls back together with the change itself. before is nil for
+// creates and after is nil for deletes.
+func recordAudit(ctx context.Context, tx *gorm.DB, entityType string, entityID uint, action string, before, after interface{}) error {
+	event, err := newAuditEvent(ctx, entityType, entityID, action, before, after)
+	if err != nil || event == nil {
+		return err
+	}
+
+	return tx.Create(event).Error
+}
+
+// newAuditEvent builds the audit event of an entity change made in ctx, or
+// returns nil for an update that changed nothing worth an entry
+func newAuditEvent(ctx context.Context, entityType string, entityID uint, action string, before, after interface{}) (*models.AuditEvent, error) {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saving an unchanged record is not worth an entry
+	if action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil, nil
+	}
+
+	return &models.AuditEvent{
+		EntityType:   entityType,
+		EntityID:     entityID,
+		Action:       action,
+		Actor:        requestctx.Actor(ctx),
+		ClaimedActor: requestctx.ClaimedActor(ctx),
+		RequestID:    requestctx.RequestID(ctx),
+		IPAddress:    requestctx.ClientIP(ctx),
+		Changes:      changes,
+	}, nil
+}
+
+// diffFields compares the JSON representation of two entity snapshots and
+// returns the fields whose values differ
+func diffFields(before, after interface{}) (models.AuditChanges, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.AuditChanges{}
+	for field, oldValue := range beforeFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if newValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[field] = models.FieldChange{Before: oldValue, After: afterFields[field]}
+		}
+	}
+	for field, newValue := range afterFields {
+		if auditIgnoredFields[field] {
+			continue
+		}
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = models.FieldChange{Before: nil, After: newValue}
+		}
+	}
+
+	return changes, nil
+}
+
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return map[string]interface{}{}, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return map[string]interface{}{}, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/metrics"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"library-backend/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrBulkRolledBack marks operations of an atomic batch that were undone or
+// never attempted because another operation in the batch failed
+var ErrBulkRolledBack = errors.New("not applied: batch rolled back")
+
+// BulkOperation is a decoded and validated operation of a bulk request
+type BulkOperation struct {
+	Op      string
+	ID      uint
+	Version uint
+	Create  *models.CreateBookRequest
+	Update  *models.UpdateBookRequest
+}
+
+// BulkOutcome is the result of one BulkOperation. Book is nil for deletes.
+type BulkOutcome struct {
+	Book *models.Book
+	Err  error
+}
+
+// BulkApply runs ops in order. In atomic mode they share one transaction and
+// the first failure rolls back the whole batch; otherwise every operation is
+// committed on its own and failures do not affect the others.
+func (s *BookService) BulkApply(ctx context.Context, ops []BulkOperation, atomic bool) []BulkOutcome {
+	ctx, span := tracing.Start(ctx, "BookService.BulkApply",
+		attribute.Int("bulk.operations", len(ops)),
+		attribute.Bool("bulk.atomic", atomic),
+	)
+	defer span.End()
+
+	outcomes := make([]BulkOutcome, len(ops))
+
+	if !atomic {
+		for i := range ops {
+			var book *models.Book
+			err := s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+				var err error
+				book, err = applyBulkOperation(ctx, repo, &ops[i])
+				return err
+			})
+			if err != nil {
+				outcomes[i] = BulkOutcome{Err: err}
+				continue
+			}
+			outcomes[i] = BulkOutcome{Book: book}
+		}
+		countCreatedBooks(ops, outcomes)
+		return outcomes
+	}
+
+	failed := -1
+	err := s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		for i := range ops {
+			book, err := applyBulkOperation(ctx, repo, &ops[i])
+			if err != nil {
+				failed = i
+				outcomes[i] = BulkOutcome{Err: err}
+				return err
+			}
+			outcomes[i] = BulkOutcome{Book: book}
+		}
+		return nil
+	})
+
+	if err != nil {
+		for i := range outcomes {
+			if i != failed {
+				outcomes[i] = BulkOutcome{Err: ErrBulkRolledBack}
+			}
+		}
+		// The commit itself failed, so no single operation is to blame
+		if failed == -1 {
+			outcomes[0].Err = err
+		}
+	}
+
+	countCreatedBooks(ops, outcomes)
+	return outcomes
+}
+
+// countCreatedBooks records the committed creates of a batch in the metrics
+func countCreatedBooks(ops []BulkOperation, outcomes []BulkOutcome) {
+	created := 0
+	for i := range ops {
+		if ops[i].Op == models.BulkOpCreate && outcomes[i].Err == nil {
+			created++
+		}
+	}
+	metrics.BooksCreated(created)
+}
+
+func applyBulkOperation(ctx context.Context, repo repository.BookRepository, op *BulkOperation) (*models.Book, error) {
+	switch op.Op {
+	case models.BulkOpCreate:
+		return createBook(ctx, repo, op.Create)
+	case models.BulkOpUpdate:
+		return updateBook(ctx, repo, op.ID, op.Update, op.Version)
+	case models.BulkOpDelete:
+		return nil, deleteBook(ctx, repo, op.ID, op.Version)
+	default:
+		return nil, newError(ErrValidation, "invalid operation: "+op.Op)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"library-backend/internal/models"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// Supported PATCH media types
+const (
+	MergePatchContentType = "application/merge-patch+json" // RFC 7396
+	JSONPatchContentType  = "application/json-patch+json"  // RFC 6902
+)
+
+var (
+	ErrUnsupportedPatchType = newError(ErrValidation, "unsupported patch media type")
+	ErrInvalidPatch         = newError(ErrValidation, "invalid patch document")
+	ErrPatchFailed          = newError(ErrValidation, "patch could not be applied")
+)
+
+// ApplyBookPatch applies a merge patch or JSON patch to the editable fields of
+// book and returns the resulting document. Every field is present in the
+// source document, so patches can clear optional fields with null or remove.
+// The result still has to be validated like a CreateBookRequest.
+func ApplyBookPatch(book *models.Book, contentType string, patch []byte) (*models.CreateBookRequest, error) {
+	original, err := json.Marshal(map[string]interface{}{
+		"title":       book.Title,
+		"author":      book.Author,
+		"year":        book.Year,
+		"isbn":        book.ISBN,
+		"description": book.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch contentType {
+	case MergePatchContentType:
+		if !json.Valid(patch) {
+			return nil, ErrInvalidPatch
+		}
+		patched, err = jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		patched, err = operations.Apply(original)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPatchFailed, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedPatchType, contentType)
+	}
+
+	// Reject patches that add fields the API does not let clients set
+	var result models.CreateBookRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPatchFailed, err)
+	}
+
+	return &result, nil
+}
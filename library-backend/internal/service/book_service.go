@@ -1,53 +1,38 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"library-backend/internal/metrics"
 	"library-backend/internal/models"
-	"library-backend/pkg/database"
+	"library-backend/internal/repository"
+	"library-backend/internal/tracing"
+	"time"
 
-	"gorm.io/gorm"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrBookNotFound    = newError(ErrNotFound, "book not found")
+	ErrBookNotInTrash  = newError(ErrNotFound, "book not in trash")
+	ErrVersionMismatch = newError(ErrPreconditionFailed, "version mismatch")
+	ErrDuplicateISBN   = newError(ErrConflict, "isbn already in use by another book")
 )
 
 type BookService struct {
-	db *database.Database
+	books repository.BookRepository
 }
 
-func NewBookService(db *database.Database) *BookService {
-	return &BookService{db: db}
+func NewBookService(books repository.BookRepository) *BookService {
+	return &BookService{books: books}
 }
 
-func (s *BookService) GetAllBooks(filter *models.BookFilter) (*models.BooksResponse, error) {
-	var books []models.Book
-	var total int64
-
-	query := s.db.Model(&models.Book{})
-
-	// Apply filters
-	if filter.Title != "" {
-		query = query.Where("title ILIKE ?", "%"+filter.Title+"%")
-	}
-	if filter.Author != "" {
-		query = query.Where("author ILIKE ?", "%"+filter.Author+"%")
-	}
-	if filter.Year != nil {
-		query = query.Where("year = ?", *filter.Year)
-	}
-
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		return nil, err
-	}
+func (s *BookService) GetAllBooks(ctx context.Context, filter *models.BookFilter) (_ *models.BooksResponse, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetAllBooks")
+	defer func() { tracing.End(span, err) }()
 
-	// Apply pagination
-	if filter.Limit > 0 {
-		query = query.Limit(filter.Limit)
-	}
-	if filter.Offset > 0 {
-		query = query.Offset(filter.Offset)
-	}
-
-	// Execute query
-	if err := query.Order("created_at DESC").Find(&books).Error; err != nil {
+	books, total, err := s.books.List(ctx, filter)
+	if err != nil {
 		return nil, err
 	}
 
@@ -60,72 +45,330 @@ func (s *BookService) GetAllBooks(filter *models.BookFilter) (*models.BooksRespo
 	}, nil
 }
 
-func (s *BookService) GetBookByID(id uint) (*models.Book, error) {
-	var book models.Book
+func (s *BookService) GetBookByID(ctx context.Context, id uint) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookByID", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
 
-	if err := s.db.First(&book, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("book not found")
+	book, err := s.books.Get(ctx, id, repository.ScopeActive)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookNotFound
 		}
 		return nil, err
 	}
 
-	return &book, nil
+	return book, nil
+}
+
+// GetBookVersion returns the current version of a book, looking in the trash
+// as well when trashed is set
+func (s *BookService) GetBookVersion(ctx context.Context, id uint, trashed bool) (_ uint, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookVersion", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	scope := repository.ScopeActive
+	if trashed {
+		scope = repository.ScopeAll
+	}
+	book, err := s.books.Get(ctx, id, scope)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrBookNotFound
+		}
+		return 0, err
+	}
+
+	return book.Version, nil
 }
 
-func (s *BookService) CreateBook(req *models.CreateBookRequest) (*models.Book, error) {
-	book := req.ToModel()
+func (s *BookService) CreateBook(ctx context.Context, req *models.CreateBookRequest) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.CreateBook")
+	defer func() { tracing.End(span, err) }()
+
+	var book *models.Book
+
+	err = s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		var err error
+		book, err = createBook(ctx, repo, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.BooksCreated(1)
+	return book, nil
+}
+
+// UpdateBook applies req to a book. A non-zero expectedVersion makes the update
+// conditional: it fails with ErrVersionMismatch if the book has changed since.
+func (s *BookService) UpdateBook(ctx context.Context, id uint, req *models.UpdateBookRequest, expectedVersion uint) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.UpdateBook", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
 
-	if err := s.db.Create(book).Error; err != nil {
+	var book *models.Book
+
+	err = s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		var err error
+		book, err = updateBook(ctx, repo, id, req, expectedVersion)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return book, nil
 }
 
-func (s *BookService) UpdateBook(id uint, req *models.UpdateBookRequest) (*models.Book, error) {
-	var book models.Book
+// DeleteBook moves a book to the trash. A non-zero expectedVersion makes the
+// delete conditional, as in UpdateBook.
+func (s *BookService) DeleteBook(ctx context.Context, id uint, expectedVersion uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BookService.DeleteBook", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		return deleteBook(ctx, repo, id, expectedVersion)
+	})
+}
+
+func createBook(ctx context.Context, repo repository.BookRepository, req *models.CreateBookRequest) (*models.Book, error) {
+	book := req.ToModel()
+
+	if err := repo.Create(ctx, book); err != nil {
+		return nil, bookError(err)
+	}
+
+	if err := auditBook(ctx, repo, book.ID, models.AuditActionCreate, nil, book); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
 
+func updateBook(ctx context.Context, repo repository.BookRepository, id uint, req *models.UpdateBookRequest, expectedVersion uint) (*models.Book, error) {
 	// Find existing book
-	if err := s.db.First(&book, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("book not found")
-		}
+	book, err := findBook(ctx, repo, id, repository.ScopeActive, expectedVersion)
+	if err != nil {
 		return nil, err
 	}
+	before := *book
 
 	// Apply updates
-	req.ApplyToModel(&book)
+	req.ApplyToModel(book)
+	book.Version++
+
+	// Save changes, guarding against a concurrent writer that got in
+	// between the read above and this write
+	if err := repo.Update(ctx, book, before.Version); err != nil {
+		return nil, bookError(err)
+	}
 
-	// Save changes
-	if err := s.db.Save(&book).Error; err != nil {
+	if err := auditBook(ctx, repo, book.ID, models.AuditActionUpdate, &before, book); err != nil {
 		return nil, err
 	}
 
-	return &book, nil
+	return book, nil
 }
 
-func (s *BookService) DeleteBook(id uint) error {
-	result := s.db.Delete(&models.Book{}, id)
+func deleteBook(ctx context.Context, repo repository.BookRepository, id uint, expectedVersion uint) error {
+	// Snapshot the book for the audit trail
+	book, err := findBook(ctx, repo, id, repository.ScopeActive, expectedVersion)
+	if err != nil {
+		return err
+	}
 
-	if result.Error != nil {
-		return result.Error
+	if err := repo.Delete(ctx, book); err != nil {
+		return bookError(err)
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("book not found")
+	return auditBook(ctx, repo, book.ID, models.AuditActionDelete, book, nil)
+}
+
+// findBook loads a book in scope for a write, checking expectedVersion
+// unless it is zero
+func findBook(ctx context.Context, repo repository.BookRepository, id uint, scope repository.Scope, expectedVersion uint) (*models.Book, error) {
+	book, err := repo.Get(ctx, id, scope)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookNotFound
+		}
+		return nil, err
+	}
+	if expectedVersion != 0 && book.Version != expectedVersion {
+		return nil, ErrVersionMismatch
 	}
+	return book, nil
+}
 
-	return nil
+// bookError turns the errors of the book repository into service errors
+func bookError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return ErrVersionMismatch
+	case errors.Is(err, repository.ErrDuplicateISBN):
+		return ErrDuplicateISBN
+	default:
+		return err
+	}
 }
 
-func (s *BookService) SearchBooks(query string) ([]models.Book, error) {
-	var books []models.Book
+// auditBook records the audit event of a book change in repo, so it commits
+// or rolls back together with the change
+func auditBook(ctx context.Context, repo repository.BookRepository, id uint, action string, before, after *models.Book) error {
+	event, err := newAuditEvent(ctx, models.AuditEntityBook, id, action, before, after)
+	if err != nil || event == nil {
+		return err
+	}
+	return repo.RecordAudit(ctx, event)
+}
+
+// PurgeBook permanently removes a book, whether or not it is in the trash.
+// A non-zero expectedVersion makes the purge conditional, as in UpdateBook.
+func (s *BookService) PurgeBook(ctx context.Context, id uint, expectedVersion uint) (err error) {
+	ctx, span := tracing.Start(ctx, "BookService.PurgeBook", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		book, err := findBook(ctx, repo, id, repository.ScopeAll, expectedVersion)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.Purge(ctx, book); err != nil {
+			return bookError(err)
+		}
+
+		return auditBook(ctx, repo, book.ID, models.AuditActionPurge, book, nil)
+	})
+}
+
+// GetDeletedBooks lists the trash, most recently deleted first
+func (s *BookService) GetDeletedBooks(ctx context.Context, limit, offset int) (_ *models.TrashResponse, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetDeletedBooks")
+	defer func() { tracing.End(span, err) }()
+
+	books, total, err := s.books.ListTrashed(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	trashed := make([]models.TrashedBook, 0, len(books))
+	for _, book := range books {
+		trashed = append(trashed, models.TrashedBook{Book: book, DeletedAt: book.DeletedAt.Time})
+	}
+
+	return &models.TrashResponse{
+		Success: true,
+		Data:    trashed,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// RestoreBook moves a book out of the trash. Restoring fails if another active
+// book has taken its ISBN in the meantime.
+func (s *BookService) RestoreBook(ctx context.Context, id uint) (_ *models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.RestoreBook", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
 
-	err := s.db.Where("title ILIKE ? OR author ILIKE ? OR description ILIKE ?",
-		"%"+query+"%", "%"+query+"%", "%"+query+"%").
-		Order("created_at DESC").
-		Find(&books).Error
+	var book *models.Book
+
+	err = s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		var err error
+		if book, err = repo.Get(ctx, id, repository.ScopeTrashed); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrBookNotInTrash
+			}
+			return err
+		}
+
+		if err := repo.Restore(ctx, book); err != nil {
+			return bookError(err)
+		}
+
+		return auditBook(ctx, repo, book.ID, models.AuditActionRestore, nil, book)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return books, err
+	return book, nil
+}
+
+// PurgeDeletedBefore permanently removes every book that was soft-deleted
+// before cutoff and returns how many were purged
+func (s *BookService) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.PurgeDeletedBefore")
+	defer func() { tracing.End(span, err) }()
+
+	var purged int64
+
+	err = s.books.Transaction(ctx, func(repo repository.BookRepository) error {
+		books, err := repo.TrashedBefore(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+
+		for i := range books {
+			if err := repo.Purge(ctx, &books[i]); err != nil {
+				return bookError(err)
+			}
+			if err := auditBook(ctx, repo, books[i].ID, models.AuditActionPurge, &books[i], nil); err != nil {
+				return err
+			}
+		}
+		purged = int64(len(books))
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// GetBookHistory returns the audit trail of a book, including deleted books
+func (s *BookService) GetBookHistory(ctx context.Context, id uint) (_ []models.AuditEvent, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.GetBookHistory", attribute.Int("book.id", int(id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.books.History(ctx, id)
+}
+
+func (s *BookService) SearchBooks(ctx context.Context, query string) (_ []models.Book, err error) {
+	ctx, span := tracing.Start(ctx, "BookService.SearchBooks")
+	defer func() { tracing.End(span, err) }()
+
+	return s.books.Search(ctx, query)
+}
+
+// bookExportBatchSize is how many books ExportBooks loads per query
+const bookExportBatchSize = 500
+
+// ExportBooks passes every book outside the trash to write in ID order,
+// loading them in batches
+func (s *BookService) ExportBooks(ctx context.Context, write func(*models.Book) error) (err error) {
+	ctx, span := tracing.Start(ctx, "BookService.ExportBooks")
+	defer func() { tracing.End(span, err) }()
+
+	var lastID uint
+	for {
+		books, err := s.books.ListAfter(ctx, lastID, bookExportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range books {
+			if err := write(&books[i]); err != nil {
+				return err
+			}
+		}
+		if len(books) < bookExportBatchSize {
+			return nil
+		}
+
+		lastID = books[len(books)-1].ID
+	}
 }
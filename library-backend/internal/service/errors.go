@@ -0,0 +1,34 @@
+package service
+
+import "errors"
+
+// Kinds of service errors. Every error the services return for a request
+// that cannot succeed as made wraps one of them, so callers can tell what
+// went wrong with errors.Is without knowing each error.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrValidation         = errors.New("validation failed")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnavailable        = errors.New("unavailable")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// kindError is a service error of a kind
+type kindError struct {
+	kind    error
+	message string
+}
+
+// newError returns an error with message that errors.Is matches to kind
+func newError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
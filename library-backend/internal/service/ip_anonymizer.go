@@ -0,0 +1,93 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"library-backend/internal/config"
+	"net"
+)
+
+// IP address storage modes
+const (
+	IPModeFull     = "full"     // store addresses as received
+	IPModeTruncate = "truncate" // keep the /24 (IPv4) or /48 (IPv6) network
+	IPModeHash     = "hash"     // store a keyed hash
+)
+
+// Length in hex characters of stored IP hashes, kept within the 45 characters
+// of the ip_address columns together with the prefix
+const ipHashLength = 40
+
+const ipHashPrefix = "h:"
+
+// IPAnonymizer rewrites client IP addresses before they are logged
+type IPAnonymizer struct {
+	mode string
+	key  []byte
+}
+
+// NewIPAnonymizer returns an anonymizer for the configured mode. The hash mode
+// falls back to truncation while no key is configured, so addresses are never
+// hashed with a guessable key.
+func NewIPAnonymizer(cfg *config.URLLogConfig) *IPAnonymizer {
+	mode := cfg.IPMode
+	if mode == IPModeHash && cfg.IPHashKey == "" {
+		mode = IPModeTruncate
+	}
+	return &IPAnonymizer{mode: mode, key: []byte(cfg.IPHashKey)}
+}
+
+// Mode is the effective storage mode
+func (a *IPAnonymizer) Mode() string {
+	return a.mode
+}
+
+// Anonymize returns the form of ip to store. Values that are not IP addresses
+// are truncated away entirely unless addresses are stored in full.
+func (a *IPAnonymizer) Anonymize(ip string) string {
+	if ip == "" || a.mode == IPModeFull {
+		return ip
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		if a.mode == IPModeHash {
+			return a.hash(ip)
+		}
+		return ""
+	}
+
+	if a.mode == IPModeHash {
+		return a.hash(parsed.String())
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// StoredForms returns the values that stand for ip alone: the address as
+// given, its canonical form and, once a hash key is configured, its hash.
+// Truncated forms are left out, as they stand for a whole network.
+func (a *IPAnonymizer) StoredForms(ip string) []string {
+	forms := []string{ip}
+	canonical := ip
+	if parsed := net.ParseIP(ip); parsed != nil {
+		canonical = parsed.String()
+		if canonical != ip {
+			forms = append(forms, canonical)
+		}
+	}
+	if len(a.key) > 0 {
+		forms = append(forms, a.hash(canonical))
+	}
+	return forms
+}
+
+func (a *IPAnonymizer) hash(value string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(value))
+	return ipHashPrefix + hex.EncodeToString(mac.Sum(nil))[:ipHashLength]
+}
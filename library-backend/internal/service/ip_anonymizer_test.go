@@ -0,0 +1,36 @@
+package service
+
+import (
+	"library-backend/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestIPAnonymizerStoredForms(t *testing.T) {
+	keyed := NewIPAnonymizer(&config.URLLogConfig{IPMode: IPModeHash, IPHashKey: "secret"})
+	hashed := keyed.Anonymize("2001:db8::1")
+
+	tests := []struct {
+		name string
+		cfg  config.URLLogConfig
+		ip   string
+		want []string
+	}{
+		{"truncate", config.URLLogConfig{IPMode: IPModeTruncate}, "1.2.3.4", []string{"1.2.3.4"}},
+		{"full", config.URLLogConfig{IPMode: IPModeFull}, "1.2.3.4", []string{"1.2.3.4"}},
+		{"canonical form", config.URLLogConfig{IPMode: IPModeTruncate}, "2001:DB8:0::1", []string{"2001:DB8:0::1", "2001:db8::1"}},
+		{"hash", config.URLLogConfig{IPMode: IPModeHash, IPHashKey: "secret"}, "2001:DB8::1", []string{"2001:DB8::1", "2001:db8::1", hashed}},
+		// Logs may have been hashed before the mode changed
+		{"key in truncate mode", config.URLLogConfig{IPMode: IPModeTruncate, IPHashKey: "secret"}, "2001:db8::1", []string{"2001:db8::1", hashed}},
+		{"not an address", config.URLLogConfig{IPMode: IPModeTruncate}, "unknown", []string{"unknown"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := NewIPAnonymizer(&test.cfg).StoredForms(test.ip)
+			if strings.Join(got, " ") != strings.Join(test.want, " ") {
+				t.Errorf("StoredForms(%q) = %q, want %q", test.ip, got, test.want)
+			}
+		})
+	}
+}
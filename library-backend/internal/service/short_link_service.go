@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/requestctx"
+	"library-backend/pkg/database"
+	"math/big"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	shortCodeAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789" // no look-alike characters
+	shortCodeAttempts = 5                                                           // generated codes tried before giving up
+	shortLinkTopLimit = 10                                                          // entries per click breakdown
+)
+
+var (
+	ErrShortLinkNotFound = newError(ErrNotFound, "short link not found")
+	ErrShortLinkExpired  = newError(ErrNotFound, "short link expired")
+	ErrAliasTaken        = newError(ErrConflict, "short link alias already in use")
+	ErrInvalidShortLink  = newError(ErrValidation, "invalid short link")
+)
+
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type ShortLinkService struct {
+	db         *database.Database
+	urls       *URLService
+	anonymizer *IPAnonymizer
+	codeLength int
+}
+
+func NewShortLinkService(db *database.Database, urls *URLService, anonymizer *IPAnonymizer, cfg *config.ShortLinkConfig) *ShortLinkService {
+	codeLength := cfg.CodeLength
+	if codeLength < 4 {
+		codeLength = 4
+	}
+	return &ShortLinkService{db: db, urls: urls, anonymizer: anonymizer, codeLength: codeLength}
+}
+
+// CreateLink stores a short link under the requested alias or a generated
+// code. Generated codes that collide with an existing code are regenerated.
+func (s *ShortLinkService) CreateLink(ctx context.Context, req *models.CreateShortLinkRequest, clientIP, userAgent string) (*models.ShortLink, error) {
+	if req.Alias != "" && !aliasPattern.MatchString(req.Alias) {
+		return nil, fmt.Errorf("%w: alias may only contain letters, digits, - and _", ErrInvalidShortLink)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidShortLink)
+	}
+
+	link := req.ToModel()
+	link.CreatedBy = requestctx.Actor(ctx)
+
+	if req.Operation == "" {
+		if err := s.urls.CheckURL(req.URL); err != nil {
+			return nil, err
+		}
+	} else {
+		processed, err := s.urls.ProcessURL(ctx, &models.URLRequest{
+			URL:           req.URL,
+			Operation:     req.Operation,
+			CanonicalMode: req.CanonicalMode,
+		}, clientIP, userAgent)
+		if err != nil {
+			return nil, err
+		}
+		link.TargetURL = processed.ProcessedURL
+	}
+
+	db := s.db.WithContext(ctx)
+
+	if req.Alias != "" {
+		link.Code = req.Alias
+		link.Custom = true
+		if err := db.Create(link).Error; err != nil {
+			if database.IsDuplicateKey(err) {
+				return nil, ErrAliasTaken
+			}
+			return nil, err
+		}
+		return link, nil
+	}
+
+	for attempt := 0; attempt < shortCodeAttempts; attempt++ {
+		code, err := randomShortCode(s.codeLength)
+		if err != nil {
+			return nil, err
+		}
+
+		link.ID = 0
+		link.Code = code
+		err = db.Create(link).Error
+		if err == nil {
+			return link, nil
+		}
+		if !database.IsDuplicateKey(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("no free short code after %d attempts", shortCodeAttempts)
+}
+
+func (s *ShortLinkService) GetLink(ctx context.Context, code string) (*models.ShortLink, error) {
+	var link models.ShortLink
+
+	if err := s.db.WithContext(ctx).Where("code = ?", code).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShortLinkNotFound
+		}
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// Follow looks up an active link for a redirect and counts the click. A
+// failure to count the click does not prevent the redirect.
+func (s *ShortLinkService) Follow(ctx context.Context, code, referrer, userAgent, clientIP string) (*models.ShortLink, error) {
+	link, err := s.GetLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if link.Expired(time.Now()) {
+		return nil, ErrShortLinkExpired
+	}
+
+	click := &models.ShortLinkClick{
+		ShortLinkID: link.ID,
+		Referrer:    referrer,
+		UserAgent:   userAgent,
+		IPAddress:   s.anonymizer.Anonymize(clientIP),
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(link).UpdateColumn("clicks", gorm.Expr("clicks + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Create(click).Error
+	})
+	if err != nil {
+		// Redirect anyway; a lost click only skews the stats
+		requestctx.Logger(ctx).WithError(err).WithField("code", link.Code).Error("Failed to record short link click")
+	}
+
+	return link, nil
+}
+
+// GetStats returns the click count of a link with its most frequent
+// referrers and user agents
+func (s *ShortLinkService) GetStats(ctx context.Context, code string) (*models.ShortLinkStats, error) {
+	link, err := s.GetLink(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.ShortLinkStats{Link: link, Clicks: link.Clicks}
+
+	var last models.ShortLinkClick
+	err = s.db.WithContext(ctx).Where("short_link_id = ?", link.ID).Order("created_at DESC").First(&last).Error
+	switch {
+	case err == nil:
+		stats.LastClickAt = &last.CreatedAt
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	if stats.Referrers, err = s.topClicks(ctx, link.ID, "referrer"); err != nil {
+		return nil, err
+	}
+	if stats.UserAgents, err = s.topClicks(ctx, link.ID, "user_agent"); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// topClicks counts the clicks of a link per value of column, most frequent
+// first. Empty values are reported as "(none)".
+func (s *ShortLinkService) topClicks(ctx context.Context, linkID uint, column string) ([]models.ClickCount, error) {
+	counts := []models.ClickCount{}
+	value := fmt.Sprintf("COALESCE(NULLIF(%s, ''), '(none)')", column)
+
+	err := s.db.WithContext(ctx).Model(&models.ShortLinkClick{}).
+		Select(value+" AS value, COUNT(*) AS clicks").
+		Where("short_link_id = ?", linkID).
+		Group(value).
+		Order("clicks DESC, value ASC").
+		Limit(shortLinkTopLimit).
+		Scan(&counts).Error
+
+	return counts, err
+}
+
+// DeleteLink removes a link together with its clicks
+func (s *ShortLinkService) DeleteLink(ctx context.Context, code string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var link models.ShortLink
+
+		if err := tx.Where("code = ?", code).First(&link).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrShortLinkNotFound
+			}
+			return err
+		}
+
+		if err := tx.Where("short_link_id = ?", link.ID).Delete(&models.ShortLinkClick{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&link).Error
+	})
+}
+
+func randomShortCode(length int) (string, error) {
+	max := big.NewInt(int64(len(shortCodeAlphabet)))
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
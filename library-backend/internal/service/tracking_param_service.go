@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/pkg/database"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTrackingParamRuleNotFound = newError(ErrNotFound, "tracking param rule not found")
+	ErrTrackingParamRuleExists   = newError(ErrConflict, "tracking param rule already exists")
+)
+
+type TrackingParamService struct {
+	db *database.Database
+
+	mu       sync.RWMutex
+	cached   []models.TrackingParamRule
+	loaded   bool
+	loadedAt time.Time
+}
+
+func NewTrackingParamService(db *database.Database) *TrackingParamService {
+	return &TrackingParamService{db: db}
+}
+
+func (s *TrackingParamService) GetRules(ctx context.Context) ([]models.TrackingParamRule, error) {
+	var rules []models.TrackingParamRule
+
+	err := s.db.WithContext(ctx).Order("domain ASC, param ASC").Find(&rules).Error
+
+	return rules, err
+}
+
+func (s *TrackingParamService) CreateRule(ctx context.Context, req *models.TrackingParamRuleRequest) (*models.TrackingParamRule, error) {
+	rule := req.ToModel()
+	rule.Domain = strings.ToLower(rule.Domain)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rule).Error; err != nil {
+			if database.IsDuplicateKey(err) {
+				return ErrTrackingParamRuleExists
+			}
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditEntityTrackingParamRule, rule.ID, models.AuditActionCreate, nil, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *TrackingParamService) DeleteRule(ctx context.Context, id uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rule models.TrackingParamRule
+
+		if err := tx.First(&rule, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTrackingParamRuleNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&rule).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditEntityTrackingParamRule, rule.ID, models.AuditActionDelete, &rule, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// Strip removes tracking parameters from the query of u in place and returns
+// the removed parameter names, sorted. The remaining parameters are re-encoded
+// sorted by key so equivalent URLs canonicalize identically.
+func (s *TrackingParamService) Strip(ctx context.Context, u *url.URL) ([]string, error) {
+	if u.RawQuery == "" {
+		return nil, nil
+	}
+
+	rules, err := s.rulesFor(ctx, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	var removed []string
+	for param := range query {
+		if isTrackingParam(param, rules) {
+			query.Del(param)
+			removed = append(removed, param)
+		}
+	}
+	sort.Strings(removed)
+
+	u.RawQuery = query.Encode()
+	return removed, nil
+}
+
+// rulesFor returns the global rules and the rules of every domain pattern
+// matching host
+func (s *TrackingParamService) rulesFor(ctx context.Context, host string) ([]models.TrackingParamRule, error) {
+	all, err := s.allRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []models.TrackingParamRule
+	for _, rule := range all {
+		if rule.Domain == "" || hostMatches(host, rule.Domain) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// allRules returns every custom rule, reloading them from the database when
+// the cache is empty or stale
+func (s *TrackingParamService) allRules(ctx context.Context) ([]models.TrackingParamRule, error) {
+	s.mu.RLock()
+	if s.loaded && time.Since(s.loadedAt) < ruleCacheTTL {
+		rules := s.cached
+		s.mu.RUnlock()
+		return rules, nil
+	}
+	s.mu.RUnlock()
+
+	var rules []models.TrackingParamRule
+	if err := s.db.WithContext(ctx).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cached = rules
+	s.loaded = true
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return rules, nil
+}
+
+func (s *TrackingParamService) invalidate() {
+	s.mu.Lock()
+	s.loaded = false
+	s.mu.Unlock()
+}
+
+// isTrackingParam decides whether param is stripped: an allow rule always
+// keeps it, otherwise a deny rule or the built-in list strips it
+func isTrackingParam(param string, rules []models.TrackingParamRule) bool {
+	denied := false
+	for _, rule := range rules {
+		if !paramMatches(param, rule.Param) {
+			continue
+		}
+		if rule.Action == models.TrackingParamAllow {
+			return false
+		}
+		denied = true
+	}
+	if denied {
+		return true
+	}
+
+	for _, pattern := range models.BuiltinTrackingParams {
+		if paramMatches(param, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// paramMatches compares parameter names case-insensitively; a pattern ending
+// in * matches any name with that prefix
+func paramMatches(param, pattern string) bool {
+	param = strings.ToLower(param)
+	pattern = strings.ToLower(pattern)
+
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(param, strings.TrimSuffix(pattern, "*"))
+	}
+	return param == pattern
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"library-backend/internal/models"
+	"library-backend/internal/requestctx"
+	"library-backend/internal/tracing"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// URLBatchOutcome is the result of one URL of a batch; exactly one of
+// Response and Err is set
+type URLBatchOutcome struct {
+	Response *models.URLResponse
+	Err      error
+}
+
+// ProcessURLs processes requests concurrently with at most workers goroutines
+// and returns the outcomes in request order. The log entries of successful
+// requests are written with bulk inserts once processing is done. Requests not
+// started before ctx is cancelled fail with the context error.
+func (s *URLService) ProcessURLs(ctx context.Context, requests []models.URLRequest, workers int, clientIP, userAgent string) []URLBatchOutcome {
+	ctx, span := tracing.Start(ctx, "URLService.ProcessURLs", attribute.Int("url.batch_size", len(requests)))
+	defer span.End()
+
+	outcomes := make([]URLBatchOutcome, len(requests))
+	logs := make([]*models.URLProcessLog, len(requests))
+
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(requests) {
+		workers = len(requests)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := ctx.Err(); err != nil {
+					outcomes[i].Err = err
+					continue
+				}
+				response, log, err := s.process(ctx, &requests[i], clientIP, userAgent)
+				outcomes[i] = URLBatchOutcome{Response: response, Err: err}
+				logs[i] = log
+			}
+		}()
+	}
+	for i := range requests {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	// Keep the logs in request order so their IDs follow the input
+	pending := make([]*models.URLProcessLog, 0, len(logs))
+	for _, log := range logs {
+		if log != nil {
+			pending = append(pending, log)
+		}
+	}
+	if len(pending) == 0 {
+		return outcomes
+	}
+
+	if err := s.logs.Create(ctx, pending...); err != nil {
+		// Log error but don't fail the requests
+		requestctx.Logger(ctx).WithError(err).WithField("urls", len(pending)).Error("Failed to log URL processing batch")
+		return outcomes
+	}
+	for i, log := range logs {
+		if log != nil {
+			outcomes[i].Response.LogID = log.ID
+		}
+	}
+
+	return outcomes
+}
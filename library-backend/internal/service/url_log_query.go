@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"time"
+)
+
+var (
+	ErrURLLogNotFound = newError(ErrNotFound, "url log not found")
+	ErrInvalidCursor  = newError(ErrValidation, "invalid cursor")
+)
+
+// Rows fetched per round trip while exporting
+const urlLogExportBatchSize = 1000
+
+// urlLogCursor marks the last log of a page
+type urlLogCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+}
+
+func (s *URLLogService) GetLog(ctx context.Context, id uint) (*models.URLProcessLog, error) {
+	log, err := s.logs.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrURLLogNotFound
+		}
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// ListLogs returns one page of the logs matching filter. Pages are chained
+// with the opaque NextCursor, which stays valid while logs are being added.
+func (s *URLLogService) ListLogs(ctx context.Context, filter *models.URLLogFilter) (*models.URLLogsResponse, error) {
+	query := s.logQuery(filter)
+
+	if filter.Cursor != "" {
+		cursor, err := decodeURLLogCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.After = &repository.URLLogCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	}
+
+	// One extra row tells whether there is a next page
+	query.Limit = filter.Limit + 1
+	logs, err := s.logs.List(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.URLLogsResponse{
+		Success: true,
+		Data:    logs,
+		Limit:   filter.Limit,
+	}
+	if len(logs) > filter.Limit {
+		response.Data = logs[:filter.Limit]
+		last := response.Data[filter.Limit-1]
+		response.NextCursor = encodeURLLogCursor(urlLogCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	return response, nil
+}
+
+// ExportLogs calls write for every log matching filter, in filter order,
+// loading the logs in batches. It stops at the first error of write.
+func (s *URLLogService) ExportLogs(ctx context.Context, filter *models.URLLogFilter, write func(*models.URLProcessLog) error) error {
+	query := s.logQuery(filter)
+	query.Limit = urlLogExportBatchSize
+
+	for {
+		logs, err := s.logs.List(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		for i := range logs {
+			if err := write(&logs[i]); err != nil {
+				return err
+			}
+		}
+		if len(logs) < urlLogExportBatchSize {
+			return nil
+		}
+
+		last := logs[len(logs)-1]
+		query.After = &repository.URLLogCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// logQuery translates the filters and the sort order of filter for the
+// repository. An IP matches its own rows only, not those truncated to its
+// network.
+func (s *URLLogService) logQuery(filter *models.URLLogFilter) *repository.URLLogQuery {
+	query := &repository.URLLogQuery{
+		Operation: filter.Operation,
+		From:      filter.From,
+		To:        filter.To,
+		Domain:    filter.Domain,
+		Sort:      filter.Sort,
+	}
+	if filter.IP != "" {
+		query.IPs = s.anonymizer.StoredForms(filter.IP)
+	}
+	return query
+}
+
+func encodeURLLogCursor(cursor urlLogCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeURLLogCursor(value string) (urlLogCursor, error) {
+	var cursor urlLogCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return cursor, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
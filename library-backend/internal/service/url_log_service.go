@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"library-backend/pkg/database"
+	"time"
+)
+
+// URLLogService manages the stored URL processing logs
+type URLLogService struct {
+	logs       repository.URLLogRepository
+	db         *database.Database // for the short link clicks
+	anonymizer *IPAnonymizer
+}
+
+func NewURLLogService(logs repository.URLLogRepository, db *database.Database, anonymizer *IPAnonymizer) *URLLogService {
+	return &URLLogService{logs: logs, db: db, anonymizer: anonymizer}
+}
+
+// ApplyRetention permanently removes the logs created before cutoff together
+// with their redirect hops. With aggregate, their daily counts per operation
+// are added to url_log_aggregates first. Returns the number of removed logs.
+func (s *URLLogService) ApplyRetention(ctx context.Context, cutoff time.Time, aggregate bool) (int64, error) {
+	return s.logs.Expire(ctx, cutoff, aggregate)
+}
+
+// EraseByIP permanently removes every URL processing log and short link click
+// recorded for ip, whether it was stored in full or hashed. Truncated rows
+// are kept, as they may belong to anyone in the same network. The logs are
+// erased before the clicks; after a failure, erasing again completes the job.
+func (s *URLLogService) EraseByIP(ctx context.Context, ip string) (*models.URLLogErasure, error) {
+	forms := s.anonymizer.StoredForms(ip)
+	erasure := &models.URLLogErasure{}
+
+	var err error
+	if erasure.ProcessLogs, erasure.RedirectHops, err = s.logs.DeleteByIP(ctx, forms); err != nil {
+		return nil, err
+	}
+
+	result := s.db.WithContext(ctx).Where("ip_address IN ?", forms).Delete(&models.ShortLinkClick{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	erasure.ShortLinkClicks = result.RowsAffected
+
+	return erasure, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/repository"
+	"library-backend/pkg/database"
+	"strings"
+	"testing"
+)
+
+// newTestDB returns a migrated in-memory SQLite database, closed at the end
+// of the test
+func newTestDB(t *testing.T) *database.Database {
+	t.Helper()
+
+	db, err := database.NewGormDB(&config.DatabaseConfig{
+		Driver: database.DriverSQLite,
+		Path:   "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory",
+	})
+	if err != nil {
+		t.Fatalf("NewGormDB: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if _, err := db.MigrateUp(context.Background()); err != nil {
+		t.Fatalf("MigrateUp: %v", err)
+	}
+	return db
+}
+
+// newNeighbourLogs returns a log service over logs and clicks of two clients
+// of the same /24, stored in full before anonymization was enabled, hashed,
+// and truncated as the current mode does
+func newNeighbourLogs(t *testing.T) (*URLLogService, *database.Database) {
+	t.Helper()
+
+	ctx := context.Background()
+	db := newTestDB(t)
+	logs := repository.NewGormURLLogRepository(db)
+	anonymizer := NewIPAnonymizer(&config.URLLogConfig{IPMode: IPModeTruncate, IPHashKey: "secret"})
+
+	for _, ip := range []string{"1.2.3.4", "1.2.3.5", anonymizer.hash("1.2.3.4"), anonymizer.hash("1.2.3.5"), "1.2.3.0"} {
+		err := logs.Create(ctx, &models.URLProcessLog{
+			OriginalURL:  "https://example.com/" + ip,
+			ProcessedURL: "https://example.com/" + ip,
+			Operation:    "canonical",
+			IPAddress:    ip,
+			Hops:         []models.URLRedirectHop{{URL: "https://example.com/", Method: "HEAD", StatusCode: 200}},
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := db.Create(&models.ShortLinkClick{ShortLinkID: 1, IPAddress: ip}).Error; err != nil {
+			t.Fatalf("Create click: %v", err)
+		}
+	}
+	return NewURLLogService(logs, db, anonymizer), db
+}
+
+func TestURLLogServiceEraseByIPKeepsNeighbours(t *testing.T) {
+	ctx := context.Background()
+	service, db := newNeighbourLogs(t)
+	anonymizer := service.anonymizer
+
+	erasure, err := service.EraseByIP(ctx, "1.2.3.4")
+	if err != nil {
+		t.Fatalf("EraseByIP: %v", err)
+	}
+	if *erasure != (models.URLLogErasure{ProcessLogs: 2, RedirectHops: 2, ShortLinkClicks: 2}) {
+		t.Errorf("EraseByIP = %+v, want 2 logs, hops and clicks", erasure)
+	}
+
+	var remainingLogs, remainingClicks []string
+	if err := db.Model(&models.URLProcessLog{}).Order("id").Pluck("ip_address", &remainingLogs).Error; err != nil {
+		t.Fatalf("remaining logs: %v", err)
+	}
+	if err := db.Model(&models.ShortLinkClick{}).Order("id").Pluck("ip_address", &remainingClicks).Error; err != nil {
+		t.Fatalf("remaining clicks: %v", err)
+	}
+	want := strings.Join([]string{"1.2.3.5", anonymizer.hash("1.2.3.5"), "1.2.3.0"}, " ")
+	if got := strings.Join(remainingLogs, " "); got != want {
+		t.Errorf("remaining logs of %s, want %s", got, want)
+	}
+	if got := strings.Join(remainingClicks, " "); got != want {
+		t.Errorf("remaining clicks of %s, want %s", got, want)
+	}
+}
+
+func TestURLLogServiceIPFilterMatchesOneClient(t *testing.T) {
+	service, _ := newNeighbourLogs(t)
+	anonymizer := service.anonymizer
+
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{"1.2.3.4", []string{"1.2.3.4", anonymizer.hash("1.2.3.4")}},
+		{"1.2.3.0", []string{"1.2.3.0"}},
+		{"1.2.3.9", nil},
+	}
+
+	for _, test := range tests {
+		response, err := service.ListLogs(context.Background(), &models.URLLogFilter{IP: test.ip, Sort: "id", Limit: 50})
+		if err != nil {
+			t.Fatalf("ListLogs(ip=%s): %v", test.ip, err)
+		}
+		var got []string
+		for _, log := range response.Data {
+			got = append(got, log.IPAddress)
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("ListLogs(ip=%s) = %q, want %q", test.ip, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var ErrInvalidURL = newError(ErrValidation, "invalid url")
+
+// Ports dropped by normalization because they are implied by the scheme
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+	"ftp":   "21",
+}
+
+// normalizeURL applies the syntax-based normalizations of RFC 3986 section 6.2.2
+// to u in place: lowercase scheme and host, IDN hosts converted to punycode,
+// default port removed, percent-encoding normalized and dot segments removed.
+// An empty fragment is dropped; the query keeps its parameter order.
+func normalizeURL(u *url.URL) (string, error) {
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	if u.Host != "" {
+		host, err := normalizeHost(u.Hostname())
+		if err != nil {
+			return "", err
+		}
+
+		port := u.Port()
+		if port == defaultPorts[u.Scheme] {
+			port = ""
+		}
+
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		if port != "" {
+			host += ":" + port
+		}
+		u.Host = host
+	}
+
+	// Opaque URLs such as mailto: have no hierarchical path to normalize
+	if u.Opaque != "" {
+		return u.String(), nil
+	}
+
+	path := removeDotSegments(normalizePercentEncoding(u.EscapedPath()))
+	unescapedPath, err := url.PathUnescape(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	u.Path = unescapedPath
+	u.RawPath = path
+
+	u.RawQuery = normalizePercentEncoding(u.RawQuery)
+
+	if u.Fragment == "" {
+		u.RawFragment = ""
+	} else {
+		fragment := normalizePercentEncoding(u.EscapedFragment())
+		unescapedFragment, err := url.PathUnescape(fragment)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+		}
+		u.Fragment = unescapedFragment
+		u.RawFragment = fragment
+	}
+
+	return u.String(), nil
+}
+
+// normalizeHost lowercases a host and converts internationalized domain names
+// to their ASCII (punycode) form
+func normalizeHost(host string) (string, error) {
+	if net.ParseIP(host) != nil {
+		return strings.ToLower(host), nil
+	}
+
+	for i := 0; i < len(host); i++ {
+		if host[i] >= 0x80 {
+			ascii, err := idna.Lookup.ToASCII(host)
+			if err != nil {
+				return "", fmt.Errorf("%w: host %q: %v", ErrInvalidURL, host, err)
+			}
+			return ascii, nil
+		}
+	}
+
+	return strings.ToLower(host), nil
+}
+
+// normalizePercentEncoding uppercases the hex digits of percent-encoded
+// octets and decodes the octets of unreserved characters
+func normalizePercentEncoding(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			c := unhex(s[i+1])<<4 | unhex(s[i+2])
+			if isUnreserved(c) {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('%')
+				b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// removeDotSegments implements the algorithm of RFC 3986 section 5.2.4
+func removeDotSegments(path string) string {
+	if !strings.Contains(path, ".") {
+		return path
+	}
+
+	var out []string
+	for path != "" {
+		switch {
+		case strings.HasPrefix(path, "../"):
+			path = path[3:]
+		case strings.HasPrefix(path, "./"):
+			path = path[2:]
+		case strings.HasPrefix(path, "/./"):
+			path = path[2:]
+		case path == "/.":
+			path = "/"
+		case strings.HasPrefix(path, "/../"):
+			path = path[3:]
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case path == "/..":
+			path = "/"
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case path == "." || path == "..":
+			path = ""
+		default:
+			// Move the first segment, with its leading slash, to the output
+			end := strings.IndexByte(path[1:], '/')
+			if end < 0 {
+				out = append(out, path)
+				path = ""
+			} else {
+				out = append(out, path[:end+1])
+				path = path[end+1:]
+			}
+		}
+	}
+	return strings.Join(out, "")
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
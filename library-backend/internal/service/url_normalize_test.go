@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string // empty when normalization fails with ErrInvalidURL
+	}{
+		{"scheme and host case", "HTTP://Example.COM/Path", "http://example.com/Path"},
+		{"dot segments", "http://example.com/a/./b/../c", "http://example.com/a/c"},
+		{"dot segments above the root", "http://example.com/../../a", "http://example.com/a"},
+
+		{"http default port", "http://example.com:80/", "http://example.com/"},
+		{"https default port", "https://example.com:443/", "https://example.com/"},
+		{"ws default port", "ws://example.com:80/", "ws://example.com/"},
+		{"other port", "https://example.com:8443/", "https://example.com:8443/"},
+		{"port of another scheme", "http://example.com:443/", "http://example.com:443/"},
+
+		{"percent-encoding case", "http://example.com/a%2fb?q=%c3%a9", "http://example.com/a%2Fb?q=%C3%A9"},
+		{"unreserved decoded", "http://example.com/%7Euser/%41%2D?q=%7e", "http://example.com/~user/A-?q=~"},
+		{"fragment", "http://example.com/a#%7esection", "http://example.com/a#~section"},
+		{"empty fragment", "http://example.com/a#", "http://example.com/a"},
+		{"query order kept", "http://example.com/?b=2&a=1", "http://example.com/?b=2&a=1"},
+
+		{"IDN", "http://Bücher.example/", "http://xn--bcher-kva.example/"},
+		{"IDN with port", "https://münchen.example:443/a", "https://xn--mnchen-3ya.example/a"},
+		{"invalid IDN", "http://xn--ü.example/", ""},
+
+		{"IPv4", "http://192.0.2.1:80/", "http://192.0.2.1/"},
+		{"IPv6", "http://[2001:DB8::1]/a", "http://[2001:db8::1]/a"},
+		{"IPv6 default port", "http://[2001:db8::1]:80/", "http://[2001:db8::1]/"},
+		{"IPv6 port", "http://[2001:db8::1]:8080/", "http://[2001:db8::1]:8080/"},
+
+		{"opaque", "mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+		{"opaque scheme case", "MAILTO:someone@example.com", "mailto:someone@example.com"},
+		{"urn", "urn:isbn:0451450523", "urn:isbn:0451450523"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatalf("url.Parse(%q): %v", test.url, err)
+			}
+			got, err := normalizeURL(u)
+
+			if test.want == "" {
+				if !errors.Is(err, ErrInvalidURL) {
+					t.Errorf("normalizeURL(%q) = %q, %v, want ErrInvalidURL", test.url, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeURL(%q): %v", test.url, err)
+			}
+			if got != test.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", test.url, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRemoveDotSegments(t *testing.T) {
+	// The examples of RFC 3986 section 5.4, as paths merged with the base
+	// path /b/c/d;p, and those of section 5.2.4
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/b/c/g", "/b/c/g"},
+		{"/b/c/./g", "/b/c/g"},
+		{"/b/c/g/", "/b/c/g/"},
+		{"/b/c/.", "/b/c/"},
+		{"/b/c/./", "/b/c/"},
+		{"/b/c/..", "/b/"},
+		{"/b/c/../", "/b/"},
+		{"/b/c/../g", "/b/g"},
+		{"/b/c/../..", "/"},
+		{"/b/c/../../", "/"},
+		{"/b/c/../../g", "/g"},
+
+		{"/b/c/../../../g", "/g"},
+		{"/b/c/../../../../g", "/g"},
+		{"/./g", "/g"},
+		{"/../g", "/g"},
+		{"/b/c/g.", "/b/c/g."},
+		{"/b/c/.g", "/b/c/.g"},
+		{"/b/c/g..", "/b/c/g.."},
+		{"/b/c/..g", "/b/c/..g"},
+		{"/b/c/./../g", "/b/g"},
+		{"/b/c/./g/.", "/b/c/g/"},
+		{"/b/c/g/./h", "/b/c/g/h"},
+		{"/b/c/g/../h", "/b/c/h"},
+		{"/b/c/g;x=1/./y", "/b/c/g;x=1/y"},
+		{"/b/c/g;x=1/../y", "/b/c/y"},
+
+		{"/a/b/c/./../../g", "/a/g"},
+		{"mid/content=5/../6", "mid/6"},
+
+		{"", ""},
+		{".", ""},
+		{"..", ""},
+		{"../a", "a"},
+		{"/", "/"},
+	}
+
+	for _, test := range tests {
+		if got := removeDotSegments(test.path); got != test.want {
+			t.Errorf("removeDotSegments(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestNormalizePercentEncoding(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"plain", "plain"},
+		{"%2f", "%2F"},
+		{"%e2%82%ac", "%E2%82%AC"},
+		{"%41%5a%61%7A%30%39", "AZaz09"},
+		{"%2D%2E%5F%7E", "-._~"},
+		{"%2B%20%25", "%2B%20%25"},
+		{"a%2", "a%2"},
+		{"%zz%4g", "%zz%4g"},
+		{"100%", "100%"},
+		{"%41", "A"},
+	}
+
+	for _, test := range tests {
+		if got := normalizePercentEncoding(test.in); got != test.want {
+			t.Errorf("normalizePercentEncoding(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
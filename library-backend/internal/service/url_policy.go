@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"library-backend/internal/config"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var ErrURLRejected = newError(ErrForbidden, "url rejected")
+
+// URLRejectedError is returned for URLs refused by the URL policy
+type URLRejectedError struct {
+	Reason string
+}
+
+func (e *URLRejectedError) Error() string {
+	return "url rejected: " + e.Reason
+}
+
+// Unwrap makes errors.Is(err, ErrURLRejected) hold for every rejection
+func (e *URLRejectedError) Unwrap() error {
+	return ErrURLRejected
+}
+
+func rejectURL(format string, args ...interface{}) error {
+	return &URLRejectedError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// Suffixes of host names that only resolve inside private networks
+var internalHostSuffixes = []string{".localhost", ".local", ".internal", ".intranet", ".lan", ".home.arpa"}
+
+// URLPolicy decides which URLs may be processed
+type URLPolicy struct {
+	cfg     *config.URLPolicyConfig
+	schemes map[string]bool
+}
+
+func NewURLPolicy(cfg *config.URLPolicyConfig) *URLPolicy {
+	schemes := make(map[string]bool, len(cfg.AllowedSchemes))
+	for _, scheme := range cfg.AllowedSchemes {
+		schemes[strings.ToLower(scheme)] = true
+	}
+	return &URLPolicy{cfg: cfg, schemes: schemes}
+}
+
+// Check returns a *URLRejectedError if u, parsed from raw, violates the policy
+func (p *URLPolicy) Check(raw string, u *url.URL) error {
+	if p.cfg.MaxLength > 0 && len(raw) > p.cfg.MaxLength {
+		return rejectURL("url is longer than %d characters", p.cfg.MaxLength)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if !p.schemes[scheme] {
+		return rejectURL("scheme %q is not allowed", u.Scheme)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return rejectURL("url has no host")
+	}
+
+	for _, pattern := range p.cfg.BlockedDomains {
+		if hostMatches(host, pattern) {
+			return rejectURL("domain %s is blocked", host)
+		}
+	}
+	if len(p.cfg.AllowedDomains) > 0 {
+		allowed := false
+		for _, pattern := range p.cfg.AllowedDomains {
+			if hostMatches(host, pattern) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return rejectURL("domain %s is not in the allowlist", host)
+		}
+	}
+
+	if !p.cfg.AllowPrivateHosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if isPrivateIP(ip) {
+				return rejectURL("address %s is private, loopback or link-local", host)
+			}
+		} else if isInternalHostname(host) {
+			return rejectURL("host %s is an internal host name", host)
+		}
+	}
+
+	return nil
+}
+
+// dialControl refuses connections to private addresses, so host names that
+// resolve to them cannot be reached either. It is meant for net.Dialer.Control.
+func (p *URLPolicy) dialControl(network, address string, _ syscall.RawConn) error {
+	if p.cfg.AllowPrivateHosts {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return rejectURL("address %s is private, loopback or link-local", host)
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// isInternalHostname reports whether host is a single-label name such as
+// localhost or uses a suffix reserved for private networks
+func isInternalHostname(host string) bool {
+	if !strings.Contains(host, ".") {
+		return true
+	}
+	for _, suffix := range internalHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"library-backend/internal/config"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestURLPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.URLPolicyConfig
+		url    string
+		reason string // empty when the URL is accepted
+	}{
+		{"public host", config.URLPolicyConfig{}, "https://example.com/a", ""},
+		{"scheme", config.URLPolicyConfig{}, "ftp://example.com/a", `scheme "ftp" is not allowed`},
+		{"scheme case", config.URLPolicyConfig{}, "HTTPS://example.com/a", ""},
+		{"no host", config.URLPolicyConfig{}, "https:///a", "url has no host"},
+		{"max length", config.URLPolicyConfig{MaxLength: 20}, "https://example.com/long", "url is longer than 20 characters"},
+
+		{"blocked domain", config.URLPolicyConfig{BlockedDomains: []string{"evil.com"}}, "https://EVIL.com./a", "domain evil.com is blocked"},
+		{"blocked wildcard", config.URLPolicyConfig{BlockedDomains: []string{"*.evil.com"}}, "https://a.b.evil.com/", "domain a.b.evil.com is blocked"},
+		{"blocked wildcard, apex", config.URLPolicyConfig{BlockedDomains: []string{"*.evil.com"}}, "https://evil.com/", ""},
+		{"blocked wildcard, other domain", config.URLPolicyConfig{BlockedDomains: []string{"*.evil.com"}}, "https://notevil.com/", ""},
+		{"allowed domain", config.URLPolicyConfig{AllowedDomains: []string{"example.com"}}, "https://example.com/", ""},
+		{"allowed wildcard", config.URLPolicyConfig{AllowedDomains: []string{"*.example.com"}}, "https://www.example.com/", ""},
+		{"allowed wildcard, apex", config.URLPolicyConfig{AllowedDomains: []string{"*.example.com"}}, "https://example.com/", "domain example.com is not in the allowlist"},
+		{"not allowed", config.URLPolicyConfig{AllowedDomains: []string{"example.com"}}, "https://example.org/", "domain example.org is not in the allowlist"},
+		{"blocked before allowed", config.URLPolicyConfig{
+			AllowedDomains: []string{"*.example.com"},
+			BlockedDomains: []string{"admin.example.com"},
+		}, "https://admin.example.com/", "domain admin.example.com is blocked"},
+
+		{"public IPv4", config.URLPolicyConfig{}, "http://93.184.216.34/", ""},
+		{"loopback", config.URLPolicyConfig{}, "http://127.0.0.1:8080/", "address 127.0.0.1 is private, loopback or link-local"},
+		{"private IPv4", config.URLPolicyConfig{}, "http://10.1.2.3/", "address 10.1.2.3 is private, loopback or link-local"},
+		{"link-local", config.URLPolicyConfig{}, "http://169.254.169.254/latest/meta-data", "address 169.254.169.254 is private, loopback or link-local"},
+		{"unspecified", config.URLPolicyConfig{}, "http://0.0.0.0/", "address 0.0.0.0 is private, loopback or link-local"},
+		{"IPv6 loopback", config.URLPolicyConfig{}, "http://[::1]/", "address ::1 is private, loopback or link-local"},
+		{"IPv6 unique local", config.URLPolicyConfig{}, "http://[fd00::1]/", "address fd00::1 is private, loopback or link-local"},
+		{"public IPv6", config.URLPolicyConfig{}, "http://[2001:4860:4860::8888]/", ""},
+		{"private allowed", config.URLPolicyConfig{AllowPrivateHosts: true}, "http://127.0.0.1/", ""},
+
+		{"single label", config.URLPolicyConfig{}, "http://localhost/", "host localhost is an internal host name"},
+		{"localhost suffix", config.URLPolicyConfig{}, "http://app.localhost/", "host app.localhost is an internal host name"},
+		{"internal suffix", config.URLPolicyConfig{}, "http://db.corp.internal/", "host db.corp.internal is an internal host name"},
+		{"home.arpa suffix", config.URLPolicyConfig{}, "http://printer.home.arpa/", "host printer.home.arpa is an internal host name"},
+		{"suffix in the middle", config.URLPolicyConfig{}, "http://internal.example.com/", ""},
+		{"internal allowed", config.URLPolicyConfig{AllowPrivateHosts: true}, "http://localhost/", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.cfg.AllowedSchemes == nil {
+				test.cfg.AllowedSchemes = []string{"http", "https"}
+			}
+			policy := NewURLPolicy(&test.cfg)
+
+			u, err := url.Parse(test.url)
+			if err != nil {
+				t.Fatalf("url.Parse(%q): %v", test.url, err)
+			}
+			err = policy.Check(test.url, u)
+
+			if test.reason == "" {
+				if err != nil {
+					t.Errorf("Check(%q) = %v, want nil", test.url, err)
+				}
+				return
+			}
+			var rejected *URLRejectedError
+			if !errors.As(err, &rejected) {
+				t.Fatalf("Check(%q) = %v, want a *URLRejectedError", test.url, err)
+			}
+			if rejected.Reason != test.reason {
+				t.Errorf("Check(%q) reason = %q, want %q", test.url, rejected.Reason, test.reason)
+			}
+			if !errors.Is(err, ErrURLRejected) || !errors.Is(err, ErrForbidden) {
+				t.Errorf("Check(%q) = %v, want it to match ErrURLRejected and ErrForbidden", test.url, err)
+			}
+		})
+	}
+}
+
+func TestURLPolicyDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		private bool
+	}{
+		{"127.0.0.1:80", true},
+		{"10.0.0.5:443", true},
+		{"192.168.1.1:8080", true},
+		{"169.254.169.254:80", true},
+		{"[::1]:443", true},
+		{"[fe80::1]:80", true},
+		{"93.184.216.34:443", false},
+		{"[2001:4860:4860::8888]:443", false},
+	}
+
+	policy := NewURLPolicy(&config.URLPolicyConfig{AllowedSchemes: []string{"http", "https"}})
+	permissive := NewURLPolicy(&config.URLPolicyConfig{AllowedSchemes: []string{"http", "https"}, AllowPrivateHosts: true})
+	for _, test := range tests {
+		err := policy.dialControl("tcp", test.address, nil)
+		if test.private && !errors.Is(err, ErrURLRejected) {
+			t.Errorf("dialControl(%s) = %v, want a rejection", test.address, err)
+		}
+		if !test.private && err != nil {
+			t.Errorf("dialControl(%s) = %v, want nil", test.address, err)
+		}
+
+		if err := permissive.dialControl("tcp", test.address, nil); err != nil {
+			t.Errorf("dialControl(%s) with private hosts allowed = %v, want nil", test.address, err)
+		}
+	}
+
+	if err := policy.dialControl("tcp", "no port", nil); err == nil || strings.Contains(err.Error(), "rejected") {
+		t.Errorf("dialControl of an invalid address = %v, want an address error", err)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"io"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"library-backend/internal/tracing"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Bytes of a GET response body read before the connection is released
+const resolveBodyDrainLimit = 64 * 1024
+
+// URLResolver follows HTTP redirects hop by hop
+type URLResolver struct {
+	cfg    *config.ResolverConfig
+	policy *URLPolicy
+	client *http.Client
+}
+
+// NewURLResolver returns a resolver that checks every hop against policy and
+// never connects to the private addresses it forbids
+func NewURLResolver(cfg *config.ResolverConfig, policy *URLPolicy) *URLResolver {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: policy.dialControl,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// Through a proxy dialControl would only see the proxy's address, and the
+	// proxy would connect to private hosts on the resolver's behalf
+	transport.Proxy = nil
+
+	return &URLResolver{
+		cfg:    cfg,
+		policy: policy,
+		client: &http.Client{
+			Transport: transport,
+			// Redirects are followed by Resolve so every hop is recorded
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Resolve requests rawURL and follows its redirects until a non-redirect
+// response, an error, a loop or the hop limit. Request failures are recorded
+// on the hop instead of being returned. The last hop holds the final URL.
+func (r *URLResolver) Resolve(ctx context.Context, rawURL string) *models.RedirectChain {
+	ctx, span := tracing.Start(ctx, "URLResolver.Resolve")
+	defer span.End()
+
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+
+	chain := &models.RedirectChain{}
+	visited := map[string]bool{rawURL: true}
+	current := rawURL
+
+	for position := 0; ; position++ {
+		hop := r.fetch(ctx, current)
+		hop.Position = position
+
+		if hop.Location == "" {
+			chain.Hops = append(chain.Hops, hop)
+			break
+		}
+
+		hop.Downgrade = isDowngrade(current, hop.Location)
+		chain.Downgrade = chain.Downgrade || hop.Downgrade
+		chain.Hops = append(chain.Hops, hop)
+
+		if visited[hop.Location] {
+			chain.Loop = true
+			break
+		}
+		if position+1 > r.cfg.MaxHops {
+			chain.MaxHopsExceeded = true
+			break
+		}
+
+		visited[hop.Location] = true
+		current = hop.Location
+	}
+
+	return chain
+}
+
+// fetch makes one request, trying HEAD first and falling back to GET when the
+// server fails or refuses HEAD
+func (r *URLResolver) fetch(ctx context.Context, rawURL string) models.URLRedirectHop {
+	hop := models.URLRedirectHop{URL: rawURL, Method: http.MethodHead}
+
+	// Redirect targets must satisfy the policy like the original URL
+	parsed, err := url.Parse(rawURL)
+	if err == nil {
+		err = r.policy.Check(rawURL, parsed)
+	}
+	if err != nil {
+		hop.Error = err.Error()
+		return hop
+	}
+
+	resp, err := r.do(ctx, http.MethodHead, rawURL)
+	if err != nil || resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if ctx.Err() != nil {
+			hop.Error = ctx.Err().Error()
+			return hop
+		}
+		hop.Method = http.MethodGet
+		resp, err = r.do(ctx, http.MethodGet, rawURL)
+	}
+	if err != nil {
+		hop.Error = err.Error()
+		return hop
+	}
+	defer resp.Body.Close()
+
+	if hop.Method == http.MethodGet {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, resolveBodyDrainLimit))
+	}
+
+	hop.StatusCode = resp.StatusCode
+	if isRedirectStatus(resp.StatusCode) {
+		// Location resolves relative references against the request URL
+		if location, err := resp.Location(); err == nil {
+			hop.Location = location.String()
+		} else if err != http.ErrNoLocation {
+			hop.Error = err.Error()
+		}
+	}
+
+	return hop
+}
+
+func (r *URLResolver) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", r.cfg.UserAgent)
+	}
+	return r.client.Do(req)
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// isDowngrade reports whether a redirect leaves https for plain http
+func isDowngrade(from, to string) bool {
+	fromURL, err := url.Parse(from)
+	if err != nil {
+		return false
+	}
+	toURL, err := url.Parse(to)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(fromURL.Scheme, "https") && strings.EqualFold(toURL.Scheme, "http")
+}
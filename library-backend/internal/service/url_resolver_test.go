@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"library-backend/internal/config"
+	"library-backend/internal/models"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestResolver returns a resolver that may reach the test servers, which
+// listen on loopback addresses
+func newTestResolver(maxHops int) *URLResolver {
+	policy := NewURLPolicy(&config.URLPolicyConfig{
+		AllowedSchemes:    []string{"http", "https"},
+		BlockedDomains:    []string{"blocked.example"},
+		AllowPrivateHosts: true,
+	})
+	return NewURLResolver(&config.ResolverConfig{MaxHops: maxHops, Timeout: 5 * time.Second, UserAgent: "resolver-test"}, policy)
+}
+
+// redirectServer serves the redirects in routes, from path to Location, and
+// answers 200 to any other path
+func redirectServer(t *testing.T, routes map[string]string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if location, ok := routes[r.URL.Path]; ok {
+			w.Header().Set("Location", location)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hopSummary describes hops as "METHOD URL STATUS" lines for comparisons
+func hopSummary(hops []models.URLRedirectHop) string {
+	lines := make([]string, 0, len(hops))
+	for i, hop := range hops {
+		line := fmt.Sprintf("%d %s %s %d", hop.Position, hop.Method, hop.URL, hop.StatusCode)
+		if hop.Position != i {
+			line += " (out of order)"
+		}
+		if hop.Error != "" {
+			line += " error: " + hop.Error
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestURLResolverFollowsRedirects(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("User-Agent"); got != "resolver-test" {
+			t.Errorf("User-Agent = %q, want resolver-test", got)
+		}
+		switch r.URL.Path {
+		case "/a":
+			w.Header().Set("Location", "/b")
+			w.WriteHeader(http.StatusMovedPermanently)
+		case "/b":
+			w.Header().Set("Location", server.URL+"/c/")
+			w.WriteHeader(http.StatusTemporaryRedirect)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	chain := newTestResolver(10).Resolve(context.Background(), server.URL+"/a")
+
+	want := strings.Join([]string{
+		"0 HEAD " + server.URL + "/a 301",
+		"1 HEAD " + server.URL + "/b 307",
+		"2 HEAD " + server.URL + "/c/ 200",
+	}, "\n")
+	if got := hopSummary(chain.Hops); got != want {
+		t.Errorf("hops:\n%s\nwant:\n%s", got, want)
+	}
+	if chain.Hops[0].Location != server.URL+"/b" {
+		t.Errorf("first hop Location = %q, want the relative /b resolved against the server", chain.Hops[0].Location)
+	}
+	if chain.Loop || chain.MaxHopsExceeded || chain.Downgrade {
+		t.Errorf("chain = %+v, want no loop, hop limit or downgrade", chain)
+	}
+}
+
+func TestURLResolverFallsBackToGet(t *testing.T) {
+	for _, status := range []int{http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var mu sync.Mutex
+			var methods []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				methods = append(methods, r.Method)
+				mu.Unlock()
+				if r.Method == http.MethodHead {
+					w.WriteHeader(status)
+					return
+				}
+				if r.URL.Path == "/start" {
+					w.Header().Set("Location", "/end")
+					w.WriteHeader(http.StatusSeeOther)
+					return
+				}
+				fmt.Fprint(w, "body")
+			}))
+			defer server.Close()
+
+			chain := newTestResolver(10).Resolve(context.Background(), server.URL+"/start")
+
+			want := "0 GET " + server.URL + "/start 303\n1 GET " + server.URL + "/end 200"
+			if got := hopSummary(chain.Hops); got != want {
+				t.Errorf("hops:\n%s\nwant:\n%s", got, want)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if got := strings.Join(methods, " "); got != "HEAD GET HEAD GET" {
+				t.Errorf("requests = %s, want HEAD then GET for every hop", got)
+			}
+		})
+	}
+}
+
+func TestURLResolverDetectsLoops(t *testing.T) {
+	server := redirectServer(t, map[string]string{"/a": "/b", "/b": "/a"})
+
+	chain := newTestResolver(10).Resolve(context.Background(), server.URL+"/a")
+
+	if !chain.Loop || chain.MaxHopsExceeded {
+		t.Errorf("chain Loop = %v, MaxHopsExceeded = %v, want a loop only", chain.Loop, chain.MaxHopsExceeded)
+	}
+	want := "0 HEAD " + server.URL + "/a 302\n1 HEAD " + server.URL + "/b 302"
+	if got := hopSummary(chain.Hops); got != want {
+		t.Errorf("hops:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestURLResolverStopsAtMaxHops(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(r.URL.Path, "/%d", &n)
+		w.Header().Set("Location", fmt.Sprintf("/%d", n+1))
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	chain := newTestResolver(3).Resolve(context.Background(), server.URL+"/0")
+
+	if !chain.MaxHopsExceeded || chain.Loop {
+		t.Errorf("chain MaxHopsExceeded = %v, Loop = %v, want the hop limit only", chain.MaxHopsExceeded, chain.Loop)
+	}
+	// The first request and the three redirects it may follow
+	if len(chain.Hops) != 4 {
+		t.Fatalf("hops:\n%s\nwant 4", hopSummary(chain.Hops))
+	}
+	if last := chain.Hops[3]; last.URL != server.URL+"/3" || last.Location != server.URL+"/4" {
+		t.Errorf("last hop = %+v, want /3 redirecting to /4", last)
+	}
+}
+
+func TestURLResolverFlagsDowngrades(t *testing.T) {
+	plain := redirectServer(t, nil)
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/stay":
+			w.Header().Set("Location", "/final")
+		case "/leave":
+			w.Header().Set("Location", plain.URL+"/final")
+		default:
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusMovedPermanently)
+	}))
+	defer secure.Close()
+
+	resolver := newTestResolver(10)
+	resolver.client.Transport.(*http.Transport).TLSClientConfig = secure.Client().Transport.(*http.Transport).TLSClientConfig
+
+	chain := resolver.Resolve(context.Background(), secure.URL+"/leave")
+	if !chain.Downgrade || len(chain.Hops) != 2 || !chain.Hops[0].Downgrade || chain.Hops[1].Downgrade {
+		t.Errorf("https to http chain = %+v, hops:\n%s\nwant the first hop flagged as a downgrade", chain, hopSummary(chain.Hops))
+	}
+	if len(chain.Hops) == 2 && chain.Hops[1].StatusCode != http.StatusOK {
+		t.Errorf("final hop status = %d, want 200", chain.Hops[1].StatusCode)
+	}
+
+	chain = resolver.Resolve(context.Background(), secure.URL+"/stay")
+	if chain.Downgrade || len(chain.Hops) != 2 || chain.Hops[0].Downgrade {
+		t.Errorf("https to https chain = %+v, hops:\n%s\nwant no downgrade", chain, hopSummary(chain.Hops))
+	}
+}
+
+func TestURLResolverChecksRedirectTargets(t *testing.T) {
+	server := redirectServer(t, map[string]string{"/a": "https://blocked.example/"})
+
+	chain := newTestResolver(10).Resolve(context.Background(), server.URL+"/a")
+
+	if len(chain.Hops) != 2 {
+		t.Fatalf("hops:\n%s\nwant 2", hopSummary(chain.Hops))
+	}
+	last := chain.Hops[1]
+	if last.URL != "https://blocked.example/" || last.StatusCode != 0 || !strings.Contains(last.Error, "domain blocked.example is blocked") {
+		t.Errorf("last hop = %+v, want the blocked target with a policy error and no request", last)
+	}
+}
+
+func TestURLResolverRefusesPrivateAddresses(t *testing.T) {
+	var requested atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(true)
+	}))
+	defer server.Close()
+
+	policy := NewURLPolicy(&config.URLPolicyConfig{AllowedSchemes: []string{"http", "https"}})
+	resolver := NewURLResolver(&config.ResolverConfig{MaxHops: 10, Timeout: 5 * time.Second}, policy)
+
+	// A public name that resolves to the loopback test server, as a DNS
+	// record pointing inside the network would. The name passes Check, so
+	// only dialControl stands between the resolver and the server.
+	transport := resolver.client.Transport.(*http.Transport)
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return dial(ctx, network, server.Listener.Addr().String())
+	}
+
+	chain := resolver.Resolve(context.Background(), "http://public.example/")
+
+	if requested.Load() {
+		t.Error("the resolver reached the server on a loopback address")
+	}
+	if len(chain.Hops) != 1 {
+		t.Fatalf("hops:\n%s\nwant 1", hopSummary(chain.Hops))
+	}
+	if hop := chain.Hops[0]; hop.StatusCode != 0 || !strings.Contains(hop.Error, "is private, loopback or link-local") {
+		t.Errorf("hop = %+v, want a dial error refusing the private address", hop)
+	}
+}
+
+func TestURLResolverIgnoresProxyEnvironment(t *testing.T) {
+	var proxied atomic.Bool
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(true)
+	}))
+	defer proxy.Close()
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("HTTPS_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "")
+
+	resolver := newTestResolver(10)
+	transport := resolver.client.Transport.(*http.Transport)
+	// http.ProxyFromEnvironment reads the environment once per process, so
+	// the transport is checked directly as well as by the request below
+	if transport.Proxy != nil {
+		t.Error("transport uses a proxy, which dialControl cannot check")
+	}
+
+	var mu sync.Mutex
+	var dialed []string
+	server := redirectServer(t, nil)
+	dial := transport.DialContext
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, address)
+		mu.Unlock()
+		return dial(ctx, network, server.Listener.Addr().String())
+	}
+
+	chain := resolver.Resolve(context.Background(), "http://public.example/")
+
+	if proxied.Load() {
+		t.Error("the request went through the proxy")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(dialed, " ") != "public.example:80" {
+		t.Errorf("dialed %q, want the target host only", dialed)
+	}
+	if len(chain.Hops) != 1 || chain.Hops[0].StatusCode != http.StatusOK {
+		t.Errorf("hops:\n%s\nwant a single 200", hopSummary(chain.Hops))
+	}
+}
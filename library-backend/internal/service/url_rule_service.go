@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library-backend/internal/models"
+	"library-backend/pkg/database"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cached URL and tracking parameter rules are reloaded after this long so
+// changes made through other server instances are picked up; changes made
+// through this instance apply at once
+const ruleCacheTTL = time.Minute
+
+var (
+	ErrURLRuleNotFound = newError(ErrNotFound, "url rule not found")
+	ErrURLRuleExists   = newError(ErrConflict, "url rule name already in use")
+	ErrInvalidRule     = newError(ErrValidation, "invalid url rule")
+)
+
+type URLRuleService struct {
+	db *database.Database
+
+	mu       sync.RWMutex
+	cached   []compiledRule
+	loadedAt time.Time
+}
+
+// compiledRule is a rule with its regular expressions parsed
+type compiledRule struct {
+	rule      models.URLRule
+	pathRegex *regexp.Regexp
+	rewrite   *regexp.Regexp
+}
+
+func NewURLRuleService(db *database.Database) *URLRuleService {
+	return &URLRuleService{db: db}
+}
+
+func (s *URLRuleService) GetRules(ctx context.Context) ([]models.URLRule, error) {
+	var rules []models.URLRule
+
+	err := s.db.WithContext(ctx).Order("priority ASC, id ASC").Find(&rules).Error
+
+	return rules, err
+}
+
+func (s *URLRuleService) GetRuleByID(ctx context.Context, id uint) (*models.URLRule, error) {
+	var rule models.URLRule
+
+	if err := s.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrURLRuleNotFound
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (s *URLRuleService) CreateRule(ctx context.Context, req *models.URLRuleRequest) (*models.URLRule, error) {
+	rule := req.ToModel()
+	if _, err := compileRule(rule); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rule).Error; err != nil {
+			if database.IsDuplicateKey(err) {
+				return ErrURLRuleExists
+			}
+			return err
+		}
+		return recordAudit(ctx, tx, models.AuditEntityURLRule, rule.ID, models.AuditActionCreate, nil, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return rule, nil
+}
+
+func (s *URLRuleService) UpdateRule(ctx context.Context, id uint, req *models.URLRuleRequest) (*models.URLRule, error) {
+	var rule models.URLRule
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rule, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrURLRuleNotFound
+			}
+			return err
+		}
+		before := rule
+
+		req.ApplyToModel(&rule)
+		if _, err := compileRule(&rule); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&rule).Error; err != nil {
+			if database.IsDuplicateKey(err) {
+				return ErrURLRuleExists
+			}
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditEntityURLRule, rule.ID, models.AuditActionUpdate, &before, &rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return &rule, nil
+}
+
+func (s *URLRuleService) DeleteRule(ctx context.Context, id uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rule models.URLRule
+
+		if err := tx.First(&rule, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrURLRuleNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&rule).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditEntityURLRule, rule.ID, models.AuditActionDelete, &rule, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// Apply rewrites u in place with every matching enabled rule, in priority
+// order, and returns the names of the rules that were applied
+func (s *URLRuleService) Apply(ctx context.Context, u *url.URL) ([]string, error) {
+	rules, err := s.activeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, rule := range rules {
+		if !rule.matches(u) {
+			continue
+		}
+
+		rule.apply(u)
+		applied = append(applied, rule.rule.Name)
+
+		if rule.rule.Final {
+			break
+		}
+	}
+
+	return applied, nil
+}
+
+// activeRules returns the compiled enabled rules, reloading them from the
+// database when the cache is empty or stale
+func (s *URLRuleService) activeRules(ctx context.Context) ([]compiledRule, error) {
+	s.mu.RLock()
+	if s.cached != nil && time.Since(s.loadedAt) < ruleCacheTTL {
+		rules := s.cached
+		s.mu.RUnlock()
+		return rules, nil
+	}
+	s.mu.RUnlock()
+
+	var rules []models.URLRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for i := range rules {
+		rule, err := compileRule(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, rule)
+	}
+
+	s.mu.Lock()
+	s.cached = compiled
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return compiled, nil
+}
+
+func (s *URLRuleService) invalidate() {
+	s.mu.Lock()
+	s.cached = nil
+	s.mu.Unlock()
+}
+
+func compileRule(rule *models.URLRule) (compiledRule, error) {
+	compiled := compiledRule{rule: *rule}
+
+	if rule.MatchPathRegex != "" {
+		re, err := regexp.Compile(rule.MatchPathRegex)
+		if err != nil {
+			return compiled, fmt.Errorf("%w: match_path_regex: %v", ErrInvalidRule, err)
+		}
+		compiled.pathRegex = re
+	}
+
+	if rule.PathRewritePattern != "" {
+		re, err := regexp.Compile(rule.PathRewritePattern)
+		if err != nil {
+			return compiled, fmt.Errorf("%w: path_rewrite_pattern: %v", ErrInvalidRule, err)
+		}
+		compiled.rewrite = re
+	}
+
+	if strings.HasPrefix(rule.MatchHost, "*") && !strings.HasPrefix(rule.MatchHost, "*.") {
+		return compiled, fmt.Errorf("%w: match_host wildcards must look like *.example.com", ErrInvalidRule)
+	}
+
+	return compiled, nil
+}
+
+func (r *compiledRule) matches(u *url.URL) bool {
+	if r.rule.MatchHost != "" && !hostMatches(u.Hostname(), r.rule.MatchHost) {
+		return false
+	}
+	if r.rule.MatchPathPrefix != "" && !strings.HasPrefix(u.Path, r.rule.MatchPathPrefix) {
+		return false
+	}
+	if r.pathRegex != nil && !r.pathRegex.MatchString(u.Path) {
+		return false
+	}
+	return true
+}
+
+func (r *compiledRule) apply(u *url.URL) {
+	if r.rule.SetScheme != "" {
+		u.Scheme = r.rule.SetScheme
+	}
+	if r.rule.SetHost != "" {
+		u.Host = r.rule.SetHost
+	}
+	if r.rewrite != nil {
+		u.Path = r.rewrite.ReplaceAllString(u.Path, r.rule.PathRewriteReplace)
+		u.RawPath = ""
+	}
+
+	switch r.rule.Lowercase {
+	case models.LowercaseHost:
+		u.Host = strings.ToLower(u.Host)
+	case models.LowercasePath:
+		u.Host = strings.ToLower(u.Host)
+		u.Path = strings.ToLower(u.Path)
+		u.RawPath = ""
+	case models.LowercaseAll:
+		u.Host = strings.ToLower(u.Host)
+		u.Path = strings.ToLower(u.Path)
+		u.RawPath = ""
+		u.RawQuery = strings.ToLower(u.RawQuery)
+		u.Fragment = strings.ToLower(u.Fragment)
+		u.RawFragment = ""
+	}
+
+	if len(r.rule.RemoveQueryParams) > 0 || len(r.rule.AddQueryParams) > 0 {
+		query := u.Query()
+		for _, param := range r.rule.RemoveQueryParams {
+			query.Del(param)
+		}
+		for key, value := range r.rule.AddQueryParams {
+			query.Set(key, value)
+		}
+
+		// Encode sorts by key, so the result does not depend on map order
+		u.RawQuery = query.Encode()
+	}
+}
+
+// hostMatches compares a host against an exact host or a *.example.com
+// wildcard, which matches subdomains but not example.com itself
+func hostMatches(host, pattern string) bool {
+	host = strings.ToLower(host)
+	pattern = strings.ToLower(pattern)
+
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
@@ -1,102 +1,159 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"library-backend/internal/metrics"
 	"library-backend/internal/models"
-	"library-backend/pkg/database"
+	"library-backend/internal/repository"
+	"library-backend/internal/requestctx"
+	"library-backend/internal/tracing"
 	"net/url"
 	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type URLService struct {
-	db *database.Database
+	logs           repository.URLLogRepository
+	rules          *URLRuleService
+	trackingParams *TrackingParamService
+	resolver       *URLResolver
+	anonymizer     *IPAnonymizer
+	policy         *URLPolicy
 }
 
-func NewURLService(db *database.Database) *URLService {
-	return &URLService{db: db}
+func NewURLService(logs repository.URLLogRepository, rules *URLRuleService, trackingParams *TrackingParamService, resolver *URLResolver, anonymizer *IPAnonymizer, policy *URLPolicy) *URLService {
+	return &URLService{
+		logs:           logs,
+		rules:          rules,
+		trackingParams: trackingParams,
+		resolver:       resolver,
+		anonymizer:     anonymizer,
+		policy:         policy,
+	}
 }
 
-func (s *URLService) ProcessURL(request *models.URLRequest, clientIP, userAgent string) (*models.URLResponse, error) {
-	parsedURL, err := url.Parse(request.URL)
+func (s *URLService) ProcessURL(ctx context.Context, request *models.URLRequest, clientIP, userAgent string) (_ *models.URLResponse, err error) {
+	ctx, span := tracing.Start(ctx, "URLService.ProcessURL", attribute.String("url.operation", request.Operation))
+	defer func() { tracing.End(span, err) }()
+
+	response, log, err := s.process(ctx, request, clientIP, userAgent)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL format: %w", err)
+		return nil, err
+	}
+
+	if err := s.logs.Create(ctx, log); err != nil {
+		// Log error but don't fail the request
+		requestctx.Logger(ctx).WithError(err).Error("Failed to log URL processing")
+	}
+	response.LogID = log.ID
+
+	return response, nil
+}
+
+// process applies the requested operation and returns the response together
+// with the log entry to persist, leaving the write to the caller
+func (s *URLService) process(ctx context.Context, request *models.URLRequest, clientIP, userAgent string) (*models.URLResponse, *models.URLProcessLog, error) {
+	parsedURL, err := s.parseURL(request.URL)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var processedURL string
+	var appliedRules, removedParams []string
+	var redirects *models.RedirectChain
 
 	switch request.Operation {
 	case "canonical":
-		processedURL = s.canonicalCleanup(parsedURL)
+		processedURL, removedParams, err = s.canonicalCleanup(ctx, parsedURL, request.CanonicalMode)
 	case "redirection":
-		processedURL = s.redirectionCleanup(parsedURL)
+		processedURL, appliedRules, err = s.redirectionCleanup(ctx, parsedURL)
+	case "normalize":
+		processedURL, err = normalizeURL(parsedURL)
+	case "resolve":
+		redirects = s.resolver.Resolve(ctx, parsedURL.String())
+		processedURL = redirects.Hops[len(redirects.Hops)-1].URL
 	case "all":
-		canonicalURL, _ := url.Parse(s.canonicalCleanup(parsedURL))
-		processedURL = s.redirectionCleanup(canonicalURL)
+		processedURL, removedParams, err = s.canonicalCleanup(ctx, parsedURL, request.CanonicalMode)
+		if err == nil {
+			canonicalURL, _ := url.Parse(processedURL)
+			processedURL, appliedRules, err = s.redirectionCleanup(ctx, canonicalURL)
+		}
 	default:
-		return nil, fmt.Errorf("invalid operation: %s", request.Operation)
+		return nil, nil, fmt.Errorf("invalid operation: %s", request.Operation)
 	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process url: %w", err)
+	}
+
+	metrics.URLProcessed(request.Operation)
 
-	// Log the operation
 	log := &models.URLProcessLog{
 		OriginalURL:  request.URL,
 		ProcessedURL: processedURL,
 		Operation:    request.Operation,
-		IPAddress:    clientIP,
+		IPAddress:    s.anonymizer.Anonymize(clientIP),
 		UserAgent:    userAgent,
 	}
-
-	if err := s.db.Create(log).Error; err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Failed to log URL processing: %v\n", err)
+	if redirects != nil {
+		log.Hops = redirects.Hops
 	}
 
 	return &models.URLResponse{
-		Success:      true,
-		ProcessedURL: processedURL,
-		Original:     request.URL,
-		Operation:    request.Operation,
-		LogID:        log.ID,
-	}, nil
+		Success:       true,
+		ProcessedURL:  processedURL,
+		Original:      request.URL,
+		Operation:     request.Operation,
+		AppliedRules:  appliedRules,
+		RemovedParams: removedParams,
+		Redirects:     redirects,
+	}, log, nil
 }
 
-func (s *URLService) canonicalCleanup(parsedURL *url.URL) string {
-	parsedURL.RawQuery = ""
-	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
-	return parsedURL.String()
+// CheckURL validates rawURL against the URL policy without processing it
+func (s *URLService) CheckURL(rawURL string) error {
+	_, err := s.parseURL(rawURL)
+	return err
 }
 
-func (s *URLService) redirectionCleanup(parsedURL *url.URL) string {
-	parsedURL.Host = "www.byfood.com"
-	return strings.ToLower(parsedURL.String())
-}
-
-func (s *URLService) GetProcessingStats() (map[string]interface{}, error) {
-	var stats struct {
-		TotalRequests int64
-		ByOperation   map[string]int64
+// parseURL parses rawURL and applies the URL policy to it
+func (s *URLService) parseURL(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w format: %v", ErrInvalidURL, err)
 	}
+	if err := s.policy.Check(rawURL, parsedURL); err != nil {
+		return nil, err
+	}
+	return parsedURL, nil
+}
 
-	// Total requests
-	s.db.Model(&models.URLProcessLog{}).Count(&stats.TotalRequests)
-
-	// By operation
-	var operationStats []struct {
-		Operation string
-		Count     int64
+// canonicalCleanup drops the trailing slash and, depending on mode, the whole
+// query string (strip_all, the default) or only its tracking parameters
+// (strip_tracking). It returns the removed parameter names in strip_tracking mode.
+func (s *URLService) canonicalCleanup(ctx context.Context, parsedURL *url.URL, mode string) (string, []string, error) {
+	var removed []string
+
+	if mode == models.CanonicalStripTracking {
+		var err error
+		removed, err = s.trackingParams.Strip(ctx, parsedURL)
+		if err != nil {
+			return "", nil, err
+		}
+	} else {
+		parsedURL.RawQuery = ""
 	}
 
-	s.db.Model(&models.URLProcessLog{}).
-		Select("operation, count(*) as count").
-		Group("operation").
-		Scan(&operationStats)
+	parsedURL.Path = strings.TrimSuffix(parsedURL.Path, "/")
+	return parsedURL.String(), removed, nil
+}
 
-	stats.ByOperation = make(map[string]int64)
-	for _, stat := range operationStats {
-		stats.ByOperation[stat.Operation] = stat.Count
+// redirectionCleanup rewrites the URL with the configured URL rules
+func (s *URLService) redirectionCleanup(ctx context.Context, parsedURL *url.URL) (string, []string, error) {
+	applied, err := s.rules.Apply(ctx, parsedURL)
+	if err != nil {
+		return "", nil, err
 	}
-
-	return map[string]interface{}{
-		"total_requests": stats.TotalRequests,
-		"by_operation":   stats.ByOperation,
-	}, nil
+	return parsedURL.String(), applied, nil
 }
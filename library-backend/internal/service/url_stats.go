@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"library-backend/internal/models"
+	"library-backend/internal/tracing"
+	"time"
+)
+
+// GetProcessingStats summarizes the URL processing logs matching filter
+func (s *URLService) GetProcessingStats(ctx context.Context, filter *models.URLStatsFilter) (_ *models.URLStats, err error) {
+	ctx, span := tracing.Start(ctx, "URLService.GetProcessingStats")
+	defer func() { tracing.End(span, err) }()
+
+	stats, err := s.logs.Stats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.From = filter.From
+	stats.To = filter.To
+	stats.Interval = filter.Interval
+	if stats.TotalRequests > 0 {
+		stats.ChangedRatio = float64(stats.ChangedRequests) / float64(stats.TotalRequests)
+	}
+	stats.Series = fillStatsGaps(stats.Series, filter.Interval)
+
+	return stats, nil
+}
+
+// fillStatsGaps inserts empty buckets between the first and the last bucket
+// so the series has one entry per interval
+func fillStatsGaps(buckets []models.URLStatsBucket, interval string) []models.URLStatsBucket {
+	if len(buckets) == 0 {
+		return []models.URLStatsBucket{}
+	}
+
+	next := func(t time.Time) time.Time {
+		switch interval {
+		case models.StatsIntervalHour:
+			return t.Add(time.Hour)
+		case models.StatsIntervalWeek:
+			return t.AddDate(0, 0, 7)
+		default:
+			return t.AddDate(0, 0, 1)
+		}
+	}
+
+	filled := make([]models.URLStatsBucket, 0, len(buckets))
+	for i, bucket := range buckets {
+		if i > 0 {
+			for start := next(filled[len(filled)-1].Start); start.Before(bucket.Start); start = next(start) {
+				filled = append(filled, models.URLStatsBucket{Start: start})
+			}
+		}
+		filled = append(filled, bucket)
+	}
+	return filled
+}
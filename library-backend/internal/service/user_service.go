@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"library-backend/internal/models"
+	"library-backend/pkg/database"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MinPasswordLength is the shortest password accepted for a user
+const MinPasswordLength = 12
+
+var (
+	ErrInvalidUser        = newError(ErrValidation, "invalid user")
+	ErrUserExists         = newError(ErrConflict, "user already exists")
+	ErrInvalidCredentials = newError(ErrForbidden, "invalid credentials")
+)
+
+// dummyPasswordHash is compared against when a username is unknown, so that
+// failed logins take as long whether or not the user exists
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return hash
+})
+
+type UserService struct {
+	db *database.Database
+}
+
+func NewUserService(db *database.Database) *UserService {
+	return &UserService{db: db}
+}
+
+// CreateUser stores a user with a bcrypt hash of password
+func (s *UserService) CreateUser(ctx context.Context, username, password, role string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || len(username) > 255 {
+		return nil, fmt.Errorf("%w: username must be 1 to 255 characters", ErrInvalidUser)
+	}
+	if !slices.Contains(models.Roles, role) {
+		return nil, fmt.Errorf("%w: role must be one of %s", ErrInvalidUser, strings.Join(models.Roles, ", "))
+	}
+	if len(password) < MinPasswordLength {
+		return nil, fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, MinPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidUser, err)
+	}
+
+	user := &models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         role,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.User{}).Where("username = ?", username).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrUserExists
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, models.AuditEntityUser, user.ID, models.AuditActionCreate, nil, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Authenticate returns the user matching username and password, or
+// ErrInvalidCredentials
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	var user models.User
+
+	err := s.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &user, nil
+}
+
+// HasAdmins reports whether any user has the admin role
+func (s *UserService) HasAdmins(ctx context.Context) (bool, error) {
+	var count int64
+
+	err := s.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count).Error
+
+	return count > 0, err
+}
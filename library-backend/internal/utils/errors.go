@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"library-backend/internal/models"
+	"library-backend/internal/service"
+	"library-backend/pkg/database"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponses maps service errors to responses, most specific first. The
+// kinds come last and catch errors without an entry of their own.
+var errorResponses = []struct {
+	err     error
+	status  int
+	message string
+	code    string
+}{
+	// Books
+	{service.ErrBookNotFound, http.StatusNotFound, "Book not found", "BOOK_NOT_FOUND"},
+	{service.ErrBookNotInTrash, http.StatusNotFound, "Book not found in trash", "BOOK_NOT_IN_TRASH"},
+	{service.ErrVersionMismatch, http.StatusPreconditionFailed, "Book has been modified", "PRECONDITION_FAILED"},
+	{service.ErrDuplicateISBN, http.StatusConflict, "Another book already uses this ISBN", "DUPLICATE_ISBN"},
+	{service.ErrBulkRolledBack, http.StatusFailedDependency, "Not applied because another operation failed", "ROLLED_BACK"},
+	{service.ErrUnsupportedPatchType, http.StatusUnsupportedMediaType, "Unsupported patch format", "UNSUPPORTED_MEDIA_TYPE"},
+	{service.ErrInvalidPatch, http.StatusBadRequest, "Invalid patch document", "INVALID_PATCH"},
+	{service.ErrPatchFailed, http.StatusUnprocessableEntity, "Patch could not be applied", "PATCH_FAILED"},
+
+	// URL rules and logs
+	{service.ErrURLRuleNotFound, http.StatusNotFound, "URL rule not found", "RULE_NOT_FOUND"},
+	{service.ErrURLRuleExists, http.StatusConflict, "Another URL rule already uses this name", "DUPLICATE_RULE"},
+	{service.ErrInvalidRule, http.StatusBadRequest, "Invalid URL rule", "INVALID_RULE"},
+	{service.ErrTrackingParamRuleNotFound, http.StatusNotFound, "Tracking parameter rule not found", "RULE_NOT_FOUND"},
+	{service.ErrTrackingParamRuleExists, http.StatusConflict, "A rule for this parameter and domain already exists", "DUPLICATE_RULE"},
+	{service.ErrURLLogNotFound, http.StatusNotFound, "URL log not found", "LOG_NOT_FOUND"},
+	{service.ErrInvalidCursor, http.StatusBadRequest, "Invalid cursor", "INVALID_CURSOR"},
+
+	// Short links, before the URL errors they may wrap
+	{service.ErrShortLinkNotFound, http.StatusNotFound, "Short link not found", "SHORT_LINK_NOT_FOUND"},
+	{service.ErrShortLinkExpired, http.StatusGone, "Short link has expired", "SHORT_LINK_EXPIRED"},
+	{service.ErrAliasTaken, http.StatusConflict, "Alias already in use", "ALIAS_TAKEN"},
+	{service.ErrInvalidShortLink, http.StatusBadRequest, "Invalid short link", "INVALID_SHORT_LINK"},
+
+	// URL processing
+	{service.ErrURLRejected, http.StatusUnprocessableEntity, "URL rejected by policy", "URL_REJECTED"},
+	{service.ErrInvalidURL, http.StatusBadRequest, "Invalid URL", "INVALID_URL"},
+
+	// Users
+	{service.ErrInvalidCredentials, http.StatusUnauthorized, "Admin credentials required", "UNAUTHORIZED"},
+	{service.ErrInvalidUser, http.StatusBadRequest, "Invalid user", "INVALID_USER"},
+	{service.ErrUserExists, http.StatusConflict, "User already exists", "USER_EXISTS"},
+
+	// Kinds
+	{service.ErrNotFound, http.StatusNotFound, "Not found", "NOT_FOUND"},
+	{service.ErrConflict, http.StatusConflict, "Conflict with the current state", "CONFLICT"},
+	{service.ErrValidation, http.StatusBadRequest, "Validation failed", "VALIDATION_FAILED"},
+	{service.ErrForbidden, http.StatusForbidden, "Forbidden", "FORBIDDEN"},
+	{service.ErrUnavailable, http.StatusServiceUnavailable, "Service temporarily unavailable", "SERVICE_UNAVAILABLE"},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, "Precondition failed", "PRECONDITION_FAILED"},
+}
+
+// ErrorFor returns the status and response for an error of the services.
+// Errors the client did not cause get a 500 with message and code, and
+// their text stays out of the response, as it may reveal internals.
+func ErrorFor(err error, message, code string) (int, *models.ErrorResponse) {
+	if database.IsUnavailable(err) {
+		err = errors.Join(service.ErrUnavailable, err)
+	}
+
+	for _, mapping := range errorResponses {
+		if !errors.Is(err, mapping.err) {
+			continue
+		}
+
+		response := &models.ErrorResponse{Success: false, Error: mapping.message, Code: mapping.code}
+		var rejected *service.URLRejectedError
+		switch {
+		case errors.As(err, &rejected):
+			response.Details = rejected.Reason
+		case mapping.status < http.StatusInternalServerError && err != mapping.err:
+			// The wrapping error tells what exactly was wrong
+			response.Details = err.Error()
+		}
+		return mapping.status, response
+	}
+
+	return http.StatusInternalServerError, &models.ErrorResponse{Success: false, Error: message, Code: code}
+}
+
+// SendServiceError sends the response for an error of the services, with
+// message and code for unexpected errors. Those are attached to the context,
+// for the error handler to log.
+func SendServiceError(c *gin.Context, err error, message, code string) {
+	status, response := ErrorFor(err, message, code)
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(err)
+	}
+
+	SendError(c, status, response.Error, response.Code, response.Details)
+}
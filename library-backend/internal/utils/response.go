@@ -2,6 +2,7 @@ package utils
 
 import (
 	"library-backend/internal/models"
+	"library-backend/internal/requestctx"
 	"net/http"
 	"time"
 
@@ -16,6 +17,7 @@ func SendError(c *gin.Context, statusCode int, message, code string, details ...
 		Error:     message,
 		Code:      code,
 		Timestamp: time.Now().Format(time.RFC3339),
+		RequestID: requestctx.RequestID(c.Request.Context()),
 	}
 
 	if len(details) > 0 {
@@ -38,6 +40,18 @@ func SendSuccess(c *gin.Context, statusCode int, message string, data interface{
 
 // SendValidationError sends validation error response
 func SendValidationError(c *gin.Context, err error) {
+	response := &models.ValidationErrorResponse{
+		Success:   false,
+		Error:     "Validation failed",
+		Fields:    ValidationFields(err),
+		RequestID: requestctx.RequestID(c.Request.Context()),
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// ValidationFields maps each invalid field of a validator error to a message
+func ValidationFields(err error) map[string]string {
 	validationErrors := make(map[string]string)
 
 	if validationErrs, ok := err.(validator.ValidationErrors); ok {
@@ -46,13 +60,7 @@ func SendValidationError(c *gin.Context, err error) {
 		}
 	}
 
-	response := &models.ValidationErrorResponse{
-		Success: false,
-		Error:   "Validation failed",
-		Fields:  validationErrors,
-	}
-
-	c.JSON(http.StatusBadRequest, response)
+	return validationErrors
 }
 
 func getValidationMessage(err validator.FieldError) string {
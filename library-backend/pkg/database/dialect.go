@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Database drivers, selected with DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Drivers lists every supported driver
+var Drivers = []string{DriverPostgres, DriverSQLite}
+
+// likeEscaper escapes the LIKE wildcards of a search term and the escape
+// character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Driver returns the driver of the connection, DriverPostgres or DriverSQLite
+func (db *Database) Driver() string {
+	return db.Dialector.Name()
+}
+
+// ContainsFold returns a condition matching rows whose column contains value,
+// ignoring case. Wildcards in value match literally. SQLite only folds the
+// case of ASCII letters.
+func (db *Database) ContainsFold(column, value string) clause.Expression {
+	pattern := "%" + likeEscaper.Replace(value) + "%"
+
+	// SQLite's LIKE already ignores case
+	operator := "ILIKE"
+	if db.Driver() == DriverSQLite {
+		operator = "LIKE"
+	}
+
+	return gorm.Expr("? "+operator+` ? ESCAPE '\'`, clause.Column{Name: column}, pattern)
+}
+
+// IsDuplicateKey reports whether err is a unique constraint violation, as
+// translated from the driver error by the dialector
+func IsDuplicateKey(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// IsUnavailable reports whether err means the database could not be reached
+// or was too busy to answer, rather than that it refused the statement
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.As(err, &netErr) ||
+		strings.Contains(err.Error(), "failed to connect") ||
+		strings.Contains(err.Error(), "too many clients") ||
+		strings.Contains(err.Error(), "the database system is") || // starting up or shutting down
+		strings.Contains(err.Error(), "database is locked") // SQLite busy timeout
+}
+
+// TruncateTime returns an expression for the start of the hour, day or week
+// (starting on Monday) of the UTC timestamps in column. Scan the result into
+// a Time, as SQLite returns it as text.
+func (db *Database) TruncateTime(unit, column string) clause.Expression {
+	if db.Driver() != DriverSQLite {
+		return gorm.Expr("date_trunc(?, ?)", unit, clause.Column{Name: column})
+	}
+
+	switch unit {
+	case "hour":
+		return gorm.Expr("strftime('%Y-%m-%d %H:00:00', ?)", clause.Column{Name: column})
+	case "week":
+		// weekday 0 moves to the next Sunday unless it is one already
+		return gorm.Expr("strftime('%Y-%m-%d 00:00:00', ?, 'weekday 0', '-6 days')", clause.Column{Name: column})
+	default:
+		return gorm.Expr("strftime('%Y-%m-%d 00:00:00', ?)", clause.Column{Name: column})
+	}
+}
+
+// sqliteTimeLayouts are the text forms of timestamps read from SQLite
+var sqliteTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// Time scans timestamps returned natively or, by SQLite for expressions such
+// as TruncateTime, as text; text without a zone is taken as UTC
+type Time struct {
+	time.Time
+}
+
+// Scan implements sql.Scanner
+func (t *Time) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time = time.Time{}
+		return nil
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", value)
+	}
+}
+
+// Value implements driver.Valuer
+func (t Time) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+func (t *Time) parse(text string) error {
+	for _, layout := range sqliteTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, time.UTC); err == nil {
+			t.Time = parsed.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a time", text)
+}
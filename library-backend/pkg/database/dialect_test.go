@@ -0,0 +1,49 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{gorm.ErrDuplicatedKey, true},
+		{fmt.Errorf("create book: %w", gorm.ErrDuplicatedKey), true},
+		{gorm.ErrForeignKeyViolated, false},
+		// Only translated errors count; message text is not matched
+		{errors.New(`duplicate key value violates unique constraint "idx_books_isbn_active"`), false},
+	}
+
+	for _, test := range tests {
+		if got := IsDuplicateKey(test.err); got != test.want {
+			t.Errorf("IsDuplicateKey(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{errors.New("database is locked (5) (SQLITE_BUSY)"), true},
+		{errors.New("FATAL: sorry, too many clients already"), true},
+		{gorm.ErrDuplicatedKey, false},
+	}
+
+	for _, test := range tests {
+		if got := IsUnavailable(test.err); got != test.want {
+			t.Errorf("IsUnavailable(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"library-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// goMigrations are the migrations that need more than SQL. Their versions
+// share one sequence with the SQL migration files.
+var goMigrations = []Migration{
+	{
+		Version: 2,
+		Name:    "default_url_rules",
+		Up:      installDefaultURLRules,
+		Down: func(tx *gorm.DB) error {
+			var names []string
+			for _, rule := range models.DefaultURLRules() {
+				names = append(names, rule.Name)
+			}
+			return tx.Where("name IN ?", names).Delete(&models.URLRule{}).Error
+		},
+	},
+}
+
+// installDefaultURLRules installs the default URL rules into an empty rule
+// table. Rule changes are audited, so a table emptied through the API on a
+// database created before versioned migrations is left empty.
+func installDefaultURLRules(tx *gorm.DB) error {
+	var rules, changes int64
+	if err := tx.Model(&models.URLRule{}).Count(&rules).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.AuditEvent{}).Where("entity_type = ?", models.AuditEntityURLRule).Count(&changes).Error; err != nil {
+		return err
+	}
+	if rules > 0 || changes > 0 {
+		return nil
+	}
+
+	defaults := models.DefaultURLRules()
+	return tx.Create(&defaults).Error
+}
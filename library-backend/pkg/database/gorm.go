@@ -1,12 +1,19 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"library-backend/internal/config"
+	"library-backend/internal/metrics"
 	"library-backend/internal/models"
+	"library-backend/internal/tracing"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -17,18 +24,49 @@ type Database struct {
 }
 
 func NewGormDB(cfg *config.DatabaseConfig) (*Database, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode, cfg.TimeZone)
+	var dialector gorm.Dialector
+	statsName := cfg.DBName
+	switch cfg.Driver {
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+			cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode, cfg.TimeZone)
+		dialector = postgres.Open(dsn)
+	case DriverSQLite:
+		// Foreign keys are off by default in SQLite; WAL lets readers
+		// continue while a write is in progress. The path may be a URI with
+		// parameters of its own, such as file:test?mode=memory.
+		path, _, hasQuery := strings.Cut(cfg.Path, "?")
+		separator := "?"
+		if hasQuery {
+			separator = "&"
+		}
+		dsn := cfg.Path + separator + "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+		dialector = sqlite.Open(dsn)
+		statsName = filepath.Base(path)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
 
 	// GORM configuration
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		// Statements are logged to stderr, leaving stdout to the output of
+		// the administrative commands
+		Logger: logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold: 200 * time.Millisecond,
+			LogLevel:      logger.Info,
+			Colorful:      true,
+		}),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
+		// The dialectors turn unique and foreign key violations into
+		// gorm.ErrDuplicatedKey and gorm.ErrForeignKeyViolated by driver
+		// error code: 23505 and 23503 on Postgres, the SQLITE_CONSTRAINT
+		// extended codes on SQLite
+		TranslateError: true,
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	db, err := gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -39,45 +77,45 @@ func NewGormDB(cfg *config.DatabaseConfig) (*Database, error) {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
 	}
 
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	if cfg.Driver == DriverSQLite {
+		// SQLite allows one writer at a time; a single connection queues
+		// writes in the pool instead of failing them with "database is
+		// locked", and keeps in-memory databases alive
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(10)
+		sqlDB.SetMaxOpenConns(100)
+		sqlDB.SetConnMaxLifetime(time.Hour)
+	}
+
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register metrics plugin: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+	if err := metrics.RegisterDBStats(sqlDB, statsName); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
 
 	log.Println("✅ Database connected successfully")
 
-	return &Database{db}, nil
+	return &Database{DB: db}, nil
 }
 
-// AutoMigrate creates/updates tables based on models
-func (db *Database) AutoMigrate() error {
-	log.Println("🔄 Running auto-migration...")
-
-	err := db.DB.AutoMigrate(
-		&models.Book{},
-		&models.URLProcessLog{},
-	)
-
+// Close closes the connection pool once in-use connections are returned
+func (db *Database) Close() error {
+	sqlDB, err := db.DB.DB()
 	if err != nil {
-		return fmt.Errorf("auto-migration failed: %w", err)
+		return err
 	}
-
-	log.Println("✅ Auto-migration completed successfully")
-	return nil
+	return sqlDB.Close()
 }
 
-// SeedData inserts sample data
-func (db *Database) SeedData() error {
-	log.Println("🌱 Seeding sample data...")
-
-	// Check if books already exist
-	var count int64
-	db.Model(&models.Book{}).Count(&count)
-	if count > 0 {
-		log.Println("📚 Sample data already exists, skipping seed")
-		return nil
-	}
-
-	sampleBooks := []models.Book{
+// SampleBooks returns the sample books inserted by the seed command
+func SampleBooks() []models.Book {
+	return []models.Book{
 		{
 			Title:       "The Go Programming Language",
 			Author:      "Alan Donovan",
@@ -106,21 +144,22 @@ func (db *Database) SeedData() error {
 			Description: "With examples in Java",
 		},
 	}
+}
 
-	result := db.Create(&sampleBooks)
-	if result.Error != nil {
-		return fmt.Errorf("failed to seed data: %w", result.Error)
+// SeedBooks inserts books unless the database already holds books, and
+// returns how many were inserted
+func (db *Database) SeedBooks(ctx context.Context, books []models.Book) (int, error) {
+	var count int64
+	if err := db.WithContext(ctx).Model(&models.Book{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count > 0 || len(books) == 0 {
+		return 0, nil
 	}
 
-	log.Printf("✅ Seeded %d sample books", len(sampleBooks))
-	return nil
-}
-
-// Health check
-func (db *Database) Health() error {
-	sqlDB, err := db.DB.DB()
-	if err != nil {
-		return err
+	if err := db.WithContext(ctx).Create(&books).Error; err != nil {
+		return 0, fmt.Errorf("failed to seed data: %w", err)
 	}
-	return sqlDB.Ping()
+
+	return len(books), nil
 }
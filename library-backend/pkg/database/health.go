@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"library-backend/internal/health"
+)
+
+// Share of the open connection limit in use at which the pool check reports
+// degraded
+const poolSaturationThreshold = 0.9
+
+// HealthChecks returns the checks of the database for a health.Registry: a
+// critical ping, the connection pool saturation and the migration state
+func (db *Database) HealthChecks() []health.Check {
+	return []health.Check{
+		{Name: "database", Critical: true, Run: db.checkPing},
+		{Name: "database_pool", Run: db.checkPool},
+		{Name: "migrations", Run: db.checkMigrations},
+	}
+}
+
+func (db *Database) checkPing(ctx context.Context) health.Result {
+	sqlDB, err := db.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		return health.Result{Status: health.StatusDown, Error: err.Error()}
+	}
+	return health.Result{Status: health.StatusUp}
+}
+
+func (db *Database) checkPool(ctx context.Context) health.Result {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return health.Result{Status: health.StatusDown, Error: err.Error()}
+	}
+
+	stats := sqlDB.Stats()
+	result := health.Result{
+		Status: health.StatusUp,
+		Details: map[string]interface{}{
+			"open":          stats.OpenConnections,
+			"in_use":        stats.InUse,
+			"idle":          stats.Idle,
+			"max_open":      stats.MaxOpenConnections,
+			"wait_count":    stats.WaitCount,
+			"wait_duration": stats.WaitDuration.String(),
+		},
+	}
+
+	// A single connection, as used for SQLite, is saturated by any query
+	if stats.MaxOpenConnections > 1 {
+		saturation := float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		result.Details["saturation"] = saturation
+		if saturation >= poolSaturationThreshold {
+			result.Status = health.StatusDegraded
+		}
+	}
+
+	return result
+}
+
+func (db *Database) checkMigrations(ctx context.Context) health.Result {
+	migrations, err := Migrations(db.Driver())
+	if err != nil {
+		return health.Result{Status: health.StatusDown, Error: err.Error()}
+	}
+
+	done, err := appliedMigrations(db.WithContext(ctx))
+	if err != nil {
+		return health.Result{Status: health.StatusDown, Error: err.Error()}
+	}
+
+	var version int64
+	var pending []int64
+	for _, migration := range migrations {
+		if _, ok := done[migration.Version]; ok {
+			version = migration.Version
+		} else {
+			pending = append(pending, migration.Version)
+		}
+	}
+
+	result := health.Result{
+		Status: health.StatusUp,
+		Details: map[string]interface{}{
+			"version": version,
+			"latest":  migrations[len(migrations)-1].Version,
+		},
+	}
+	if len(pending) > 0 {
+		result.Status = health.StatusDegraded
+		result.Error = "migrations pending"
+		result.Details["pending"] = pending
+	}
+
+	return result
+}
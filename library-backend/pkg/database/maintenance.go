@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm/clause"
+)
+
+// Reindex rebuilds the indexes of tables and refreshes their planner
+// statistics, all tables when none are given. Tables are processed one at a
+// time, and writes to each are blocked while its indexes are rebuilt.
+func (db *Database) Reindex(ctx context.Context, tables ...string) ([]string, error) {
+	existing, err := db.WithContext(ctx).Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		tables = existing
+		slices.Sort(tables)
+	}
+
+	for _, table := range tables {
+		if !slices.Contains(existing, table) {
+			return nil, fmt.Errorf("unknown table %q", table)
+		}
+	}
+
+	// SQLite takes the table name without the TABLE keyword
+	reindex := "REINDEX TABLE ?"
+	if db.Driver() == DriverSQLite {
+		reindex = "REINDEX ?"
+	}
+
+	var done []string
+	for _, table := range tables {
+		if err := db.WithContext(ctx).Exec(reindex, clause.Table{Name: table}).Error; err != nil {
+			return done, fmt.Errorf("reindex %s: %w", table, err)
+		}
+		if err := db.WithContext(ctx).Exec("ANALYZE ?", clause.Table{Name: table}).Error; err != nil {
+			return done, fmt.Errorf("analyze %s: %w", table, err)
+		}
+		done = append(done, table)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,335 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SQL migrations are kept per driver in migrations/<driver>; every driver
+// has the same versions
+//
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// Arbitrary key of the Postgres advisory lock held while migrating, so that
+// only one instance migrates at a time
+const migrationLockKey int64 = 0x6c6962726172 // "librar"
+
+var createSchemaMigrations = map[string]string{
+	DriverPostgres: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    applied_at timestamptz NOT NULL
+)`,
+	DriverSQLite: `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    bigint PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    applied_at datetime NOT NULL
+)`,
+}
+
+// Names of SQL migration files: <version>_<name>.up.sql or .down.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change. SQL migrations are embedded from
+// the migrations directory of the driver, Go migrations are listed in
+// goMigrations and shared by all drivers. Each
+// migration runs in a transaction together with its schema_migrations row.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error // nil for irreversible migrations
+}
+
+// MigrationState is a known migration and whether it is applied
+type MigrationState struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// schemaMigration is a row of schema_migrations
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations returns every known migration for driver in version order
+func Migrations(driver string) ([]Migration, error) {
+	byVersion := make(map[int64]*Migration)
+
+	migrationsDir := path.Join("migrations", driver)
+	entries, err := fs.ReadDir(migrationFiles, migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+
+		data, err := migrationFiles.ReadFile(path.Join(migrationsDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = execSQL(string(data))
+		} else {
+			migration.Down = execSQL(string(data))
+		}
+	}
+
+	for _, migration := range goMigrations {
+		if _, ok := byVersion[migration.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d", migration.Version)
+		}
+		migration := migration
+		byVersion[migration.Version] = &migration
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func execSQL(sql string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(sql).Error
+	}
+}
+
+// MigrateUp applies every pending migration in version order and returns the
+// applied ones
+func (db *Database) MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := Migrations(db.Driver())
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = db.withMigrationLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first, and
+// returns the reverted ones
+func (db *Database) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := Migrations(db.Driver())
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int64]Migration, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = db.withMigrationLock(ctx, func(conn *gorm.DB) error {
+		var rows []schemaMigration
+		if err := conn.Order("version DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			migration, ok := known[row.Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s is applied but unknown to this binary", row.Version, row.Name)
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// MigrationStatus lists every known migration with its state, followed by
+// applied migrations this binary does not know
+func (db *Database) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	migrations, err := Migrations(db.Driver())
+	if err != nil {
+		return nil, err
+	}
+
+	// Status must not write, so a database never migrated has nothing applied
+	done := map[int64]schemaMigration{}
+	conn := db.WithContext(ctx)
+	if conn.Migrator().HasTable(&schemaMigration{}) {
+		if done, err = appliedMigrations(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, migration := range migrations {
+		state := MigrationState{Version: migration.Version, Name: migration.Name}
+		if row, ok := done[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			delete(done, migration.Version)
+		}
+		states = append(states, state)
+	}
+
+	unknown := make([]MigrationState, 0, len(done))
+	for _, row := range done {
+		appliedAt := row.AppliedAt
+		unknown = append(unknown, MigrationState{Version: row.Version, Name: row.Name, Applied: true, AppliedAt: &appliedAt})
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Version < unknown[j].Version })
+
+	return append(states, unknown...), nil
+}
+
+// withMigrationLock runs fn on a single connection holding the migration
+// advisory lock, waiting for other instances to finish migrating first.
+// SQLite has no advisory locks; each migration's transaction locks the file.
+func (db *Database) withMigrationLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// Connection hands over its statement; start a new one per query
+		conn = conn.Session(&gorm.Session{NewDB: true})
+
+		if db.Driver() == DriverPostgres {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+			// The lock belongs to the pooled connection, so release it even
+			// when ctx is cancelled, or the next migration would wait forever
+			defer func() {
+				unlock := conn.WithContext(context.WithoutCancel(ctx))
+				if err := unlock.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+					log.Printf("⚠️ Failed to release migration lock: %v", err)
+				}
+			}()
+		}
+
+		if err := conn.Exec(createSchemaMigrations[db.Driver()]).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(conn)
+	})
+}
+
+func appliedMigrations(conn *gorm.DB) (map[int64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := conn.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// CreateMigration writes empty up and down SQL files for a new migration to
+// the directory of every driver under dir, versioned by the current UTC
+// time, and returns their paths
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	version := time.Now().UTC().Format("20060102150405")
+
+	var files []string
+	for _, driver := range Drivers {
+		up := filepath.Join(dir, driver, version+"_"+name+".up.sql")
+		down := filepath.Join(dir, driver, version+"_"+name+".down.sql")
+
+		if err := os.WriteFile(up, []byte("-- "+name+"\n"), 0o644); err != nil {
+			return files, err
+		}
+		files = append(files, up)
+		if err := os.WriteFile(down, []byte("-- Revert "+name+"\n"), 0o644); err != nil {
+			return files, err
+		}
+		files = append(files, down)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS tracking_param_rules;
+DROP TABLE IF EXISTS url_rules;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS short_link_clicks;
+DROP TABLE IF EXISTS short_links;
+DROP TABLE IF EXISTS url_log_aggregates;
+DROP TABLE IF EXISTS url_redirect_hops;
+DROP TABLE IF EXISTS url_process_logs;
+DROP TABLE IF EXISTS books;
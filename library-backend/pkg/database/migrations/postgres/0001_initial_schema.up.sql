@@ -0,0 +1,144 @@
+-- Baseline schema, matching what AutoMigrate created before versioned
+-- migrations. Every statement is guarded so databases created by AutoMigrate
+-- adopt this version: missing tables and indexes are created, and columns
+-- added to existing tables since the first release are added to them.
+
+CREATE TABLE IF NOT EXISTS books (
+    id          bigserial PRIMARY KEY,
+    title       varchar(255) NOT NULL,
+    author      varchar(255) NOT NULL,
+    year        bigint NOT NULL,
+    isbn        varchar(13),
+    description text,
+    version     bigint NOT NULL DEFAULT 1,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    CONSTRAINT chk_books_year CHECK (year >= 1000 AND year <= 2024)
+);
+-- Added for optimistic locking; CREATE TABLE IF NOT EXISTS leaves the books
+-- table of the first release without it
+ALTER TABLE books ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_books_title ON books (title);
+CREATE INDEX IF NOT EXISTS idx_books_author ON books (author);
+CREATE INDEX IF NOT EXISTS idx_books_year ON books (year);
+CREATE INDEX IF NOT EXISTS idx_books_deleted_at ON books (deleted_at);
+-- The ISBN index used to cover soft-deleted rows, which blocked re-adding
+-- a book whose previous copy was in the trash
+DROP INDEX IF EXISTS idx_books_isbn;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_books_isbn_active ON books (isbn) WHERE deleted_at IS NULL AND isbn <> '';
+
+CREATE TABLE IF NOT EXISTS url_process_logs (
+    id            bigserial PRIMARY KEY,
+    original_url  text NOT NULL,
+    processed_url text NOT NULL,
+    operation     varchar(20) NOT NULL,
+    ip_address    varchar(45),
+    user_agent    text,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_url_process_logs_deleted_at ON url_process_logs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS url_redirect_hops (
+    id          bigserial PRIMARY KEY,
+    log_id      bigint NOT NULL,
+    position    bigint NOT NULL,
+    url         text NOT NULL,
+    method      varchar(10) NOT NULL,
+    status_code bigint,
+    location    text,
+    downgrade   boolean NOT NULL,
+    error       text,
+    created_at  timestamptz,
+    CONSTRAINT fk_url_process_logs_hops FOREIGN KEY (log_id) REFERENCES url_process_logs (id)
+);
+CREATE INDEX IF NOT EXISTS idx_url_redirect_hops_log_id ON url_redirect_hops (log_id);
+
+CREATE TABLE IF NOT EXISTS url_log_aggregates (
+    id         bigserial PRIMARY KEY,
+    day        timestamptz NOT NULL,
+    operation  varchar(20) NOT NULL,
+    requests   bigint NOT NULL,
+    changed    bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_log_aggregate ON url_log_aggregates (day, operation);
+
+CREATE TABLE IF NOT EXISTS short_links (
+    id            bigserial PRIMARY KEY,
+    code          varchar(64) NOT NULL,
+    target_url    text NOT NULL,
+    redirect_type bigint NOT NULL,
+    custom        boolean NOT NULL,
+    expires_at    timestamptz,
+    clicks        bigint NOT NULL,
+    created_by    varchar(255),
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_short_links_code ON short_links (code);
+CREATE INDEX IF NOT EXISTS idx_short_links_expires_at ON short_links (expires_at);
+
+CREATE TABLE IF NOT EXISTS short_link_clicks (
+    id            bigserial PRIMARY KEY,
+    short_link_id bigint NOT NULL,
+    referrer      text,
+    user_agent    text,
+    ip_address    varchar(45),
+    created_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_short_link_clicks_short_link_id ON short_link_clicks (short_link_id);
+CREATE INDEX IF NOT EXISTS idx_short_link_clicks_created_at ON short_link_clicks (created_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id          bigserial PRIMARY KEY,
+    entity_type varchar(50) NOT NULL,
+    entity_id   bigint NOT NULL,
+    action      varchar(20) NOT NULL,
+    actor       varchar(255) NOT NULL,
+    request_id  varchar(64),
+    ip_address  varchar(45),
+    changes     text,
+    created_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX IF NOT EXISTS idx_audit_events_request_id ON audit_events (request_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE TABLE IF NOT EXISTS url_rules (
+    id                   bigserial PRIMARY KEY,
+    name                 varchar(100) NOT NULL,
+    description          text,
+    priority             bigint NOT NULL,
+    enabled              boolean NOT NULL,
+    final                boolean NOT NULL,
+    match_host           varchar(255),
+    match_path_prefix    text,
+    match_path_regex     text,
+    set_scheme           varchar(10),
+    set_host             varchar(255),
+    path_rewrite_pattern text,
+    path_rewrite_replace text,
+    lowercase            varchar(10) NOT NULL,
+    add_query_params     text,
+    remove_query_params  text,
+    created_at           timestamptz,
+    updated_at           timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_url_rules_name ON url_rules (name);
+CREATE INDEX IF NOT EXISTS idx_url_rules_priority ON url_rules (priority);
+
+CREATE TABLE IF NOT EXISTS tracking_param_rules (
+    id         bigserial PRIMARY KEY,
+    domain     varchar(255) NOT NULL,
+    param      varchar(100) NOT NULL,
+    action     varchar(10) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracking_param_rule ON tracking_param_rules (domain, param);
@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id            bigserial PRIMARY KEY,
+    username      varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    role          varchar(20) NOT NULL,
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+CREATE UNIQUE INDEX idx_users_username ON users (username);
@@ -0,0 +1,3 @@
+-- The X-Actor header of anonymous requests, kept apart from the actor as
+-- nothing verifies it
+ALTER TABLE audit_events ADD COLUMN claimed_actor varchar(255);
@@ -0,0 +1,137 @@
+-- Baseline schema for SQLite, matching the Postgres baseline. Types follow
+-- SQLite's affinities; datetime columns let the driver return times.
+
+CREATE TABLE books (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    title       varchar(255) NOT NULL,
+    author      varchar(255) NOT NULL,
+    year        bigint NOT NULL,
+    isbn        varchar(13),
+    description text,
+    version     bigint NOT NULL DEFAULT 1,
+    created_at  datetime,
+    updated_at  datetime,
+    deleted_at  datetime,
+    CONSTRAINT chk_books_year CHECK (year >= 1000 AND year <= 2024)
+);
+CREATE INDEX idx_books_title ON books (title);
+CREATE INDEX idx_books_author ON books (author);
+CREATE INDEX idx_books_year ON books (year);
+CREATE INDEX idx_books_deleted_at ON books (deleted_at);
+-- Soft-deleted books do not block re-adding a book with the same ISBN
+CREATE UNIQUE INDEX idx_books_isbn_active ON books (isbn) WHERE deleted_at IS NULL AND isbn <> '';
+
+CREATE TABLE url_process_logs (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    original_url  text NOT NULL,
+    processed_url text NOT NULL,
+    operation     varchar(20) NOT NULL,
+    ip_address    varchar(45),
+    user_agent    text,
+    created_at    datetime,
+    updated_at    datetime,
+    deleted_at    datetime
+);
+CREATE INDEX idx_url_process_logs_deleted_at ON url_process_logs (deleted_at);
+
+CREATE TABLE url_redirect_hops (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    log_id      bigint NOT NULL,
+    position    bigint NOT NULL,
+    url         text NOT NULL,
+    method      varchar(10) NOT NULL,
+    status_code bigint,
+    location    text,
+    downgrade   boolean NOT NULL,
+    error       text,
+    created_at  datetime,
+    CONSTRAINT fk_url_process_logs_hops FOREIGN KEY (log_id) REFERENCES url_process_logs (id)
+);
+CREATE INDEX idx_url_redirect_hops_log_id ON url_redirect_hops (log_id);
+
+CREATE TABLE url_log_aggregates (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    day        datetime NOT NULL,
+    operation  varchar(20) NOT NULL,
+    requests   bigint NOT NULL,
+    changed    bigint NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE UNIQUE INDEX idx_url_log_aggregate ON url_log_aggregates (day, operation);
+
+CREATE TABLE short_links (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    code          varchar(64) NOT NULL,
+    target_url    text NOT NULL,
+    redirect_type bigint NOT NULL,
+    custom        boolean NOT NULL,
+    expires_at    datetime,
+    clicks        bigint NOT NULL,
+    created_by    varchar(255),
+    created_at    datetime,
+    updated_at    datetime
+);
+CREATE UNIQUE INDEX idx_short_links_code ON short_links (code);
+CREATE INDEX idx_short_links_expires_at ON short_links (expires_at);
+
+CREATE TABLE short_link_clicks (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    short_link_id bigint NOT NULL,
+    referrer      text,
+    user_agent    text,
+    ip_address    varchar(45),
+    created_at    datetime
+);
+CREATE INDEX idx_short_link_clicks_short_link_id ON short_link_clicks (short_link_id);
+CREATE INDEX idx_short_link_clicks_created_at ON short_link_clicks (created_at);
+
+CREATE TABLE audit_events (
+    id          integer PRIMARY KEY AUTOINCREMENT,
+    entity_type varchar(50) NOT NULL,
+    entity_id   bigint NOT NULL,
+    action      varchar(20) NOT NULL,
+    actor       varchar(255) NOT NULL,
+    request_id  varchar(64),
+    ip_address  varchar(45),
+    changes     text,
+    created_at  datetime
+);
+CREATE INDEX idx_audit_entity ON audit_events (entity_type, entity_id);
+CREATE INDEX idx_audit_events_action ON audit_events (action);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor);
+CREATE INDEX idx_audit_events_request_id ON audit_events (request_id);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+
+CREATE TABLE url_rules (
+    id                   integer PRIMARY KEY AUTOINCREMENT,
+    name                 varchar(100) NOT NULL,
+    description          text,
+    priority             bigint NOT NULL,
+    enabled              boolean NOT NULL,
+    final                boolean NOT NULL,
+    match_host           varchar(255),
+    match_path_prefix    text,
+    match_path_regex     text,
+    set_scheme           varchar(10),
+    set_host             varchar(255),
+    path_rewrite_pattern text,
+    path_rewrite_replace text,
+    lowercase            varchar(10) NOT NULL,
+    add_query_params     text,
+    remove_query_params  text,
+    created_at           datetime,
+    updated_at           datetime
+);
+CREATE UNIQUE INDEX idx_url_rules_name ON url_rules (name);
+CREATE INDEX idx_url_rules_priority ON url_rules (priority);
+
+CREATE TABLE tracking_param_rules (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    domain     varchar(255) NOT NULL,
+    param      varchar(100) NOT NULL,
+    action     varchar(10) NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE UNIQUE INDEX idx_tracking_param_rule ON tracking_param_rules (domain, param);
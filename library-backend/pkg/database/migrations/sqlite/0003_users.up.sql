@@ -0,0 +1,9 @@
+CREATE TABLE users (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    username      varchar(255) NOT NULL,
+    password_hash varchar(255) NOT NULL,
+    role          varchar(20) NOT NULL,
+    created_at    datetime,
+    updated_at    datetime
+);
+CREATE UNIQUE INDEX idx_users_username ON users (username);